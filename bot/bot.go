@@ -0,0 +1,292 @@
+// Package bot plays strategies written as external programs, which speak
+// line-delimited JSON over their standard input and output.
+//
+// At the start of each game the bot is sent
+//
+//	{"type": "start", "rules": {...}}
+//
+// and at the start of each year
+//
+//	{"type": "state", "year": 0, "population": 100, "acreage": 1000, "grain": 2800, "price": 21,
+//	 "bid": 21, "ask": 21, "harvest_per_acre": 3, "rat_percent": 7, "plague": false, "starvation_victims": 0, "immigrants": 5}
+//
+// to which it must reply, within the move timeout, with one line
+//
+//	{"acres_to_buy": 0, "acres_to_sell": 0, "grain_for_food": 2000, "acres_to_plant": 1000}
+//
+// Year is the years of rule so far. Bid and ask are what selling and buying
+// an acre earn and cost, which differ from the price when the land market has
+// a spread. The harvest, rats, plague, victims and immigrants are last year's.
+// If the rules have weather, "regime" is last year's weather and "forecast",
+// if the rules give one, this year's. If the rules have demographics,
+// "children", "workers" and "elders" divide up the population; only workers
+// tend the fields. Bots must flush their output after each reply. A bot that
+// is too slow, replies with anything else or exits forfeits the game: it is
+// killed, and the kingdom is left unfed and unplanted. It is started again
+// for the next game.
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"io"
+	"os/exec"
+	"time"
+)
+
+var (
+	ErrTimeout   = errors.New("bot took too long to move")
+	ErrMalformed = errors.New("malformed reply from bot")
+	ErrExited    = errors.New("bot exited")
+)
+
+// Start is the message sent at the start of each game.
+type Start struct {
+	Type  string             `json:"type"`
+	Rules kingdomstate.Rules `json:"rules"`
+}
+
+// State is the message sent at the start of each year.
+type State struct {
+	Type              string `json:"type"`
+	Year              uint   `json:"year"`
+	Population        uint   `json:"population"`
+	Acreage           uint   `json:"acreage"`
+	Grain             uint   `json:"grain"`
+	Price             uint   `json:"price"`
+	Bid               uint   `json:"bid"` // what selling an acre earns
+	Ask               uint   `json:"ask"` // what buying an acre costs
+	HarvestPerAcre    uint   `json:"harvest_per_acre"`
+	RatPercent        uint   `json:"rat_percent"`
+	Plague            bool   `json:"plague"`
+	StarvationVictims uint   `json:"starvation_victims"`
+	Immigrants        uint   `json:"immigrants"`
+
+	// Only if the rules have weather
+	Regime   *kingdomstate.Regime `json:"regime,omitempty"`   // last year's weather
+	Forecast *kingdomstate.Regime `json:"forecast,omitempty"` // of this year's, if the rules give one
+
+	// Only if the rules have demographics
+	Children *uint `json:"children,omitempty"`
+	Workers  *uint `json:"workers,omitempty"`
+	Elders   *uint `json:"elders,omitempty"`
+}
+
+// Decision is the bot's reply.
+type Decision struct {
+	AcresToBuy   uint `json:"acres_to_buy"`
+	AcresToSell  uint `json:"acres_to_sell"`
+	GrainForFood uint `json:"grain_for_food"`
+	AcresToPlant uint `json:"acres_to_plant"`
+}
+
+// NewState makes the message describing the kingdom at the start of a year.
+func NewState(ks kingdomstate.KingdomState) State {
+	r := ks.LastReport()
+	s := State{
+		Type:              "state",
+		Year:              ks.YearOfRule(),
+		Population:        ks.Population(),
+		Acreage:           ks.Acreage(),
+		Grain:             ks.Grain(),
+		Price:             ks.NextYearPricePerAcre(),
+		Bid:               ks.NextYearBidPerAcre(),
+		Ask:               ks.NextYearAskPerAcre(),
+		HarvestPerAcre:    r.HarvestPerAcre,
+		RatPercent:        r.PercentEatenByRats,
+		Plague:            r.PlagueHappened,
+		StarvationVictims: r.StarvationVictims,
+		Immigrants:        r.Immigrants,
+	}
+	if ks.Rules().Weather.Enabled {
+		regime := ks.Regime()
+		s.Regime = &regime
+	}
+	if forecast, ok := ks.Forecast(); ok {
+		s.Forecast = &forecast
+	}
+	if ks.Rules().Demographics.Enabled {
+		children, workers, elders := ks.Children(), ks.Workers(), ks.Elders()
+		s.Children, s.Workers, s.Elders = &children, &workers, &elders
+	}
+	return s
+}
+
+// Config describes how to run a bot.
+type Config struct {
+	Command     []string      // program and arguments
+	MoveTimeout time.Duration // longest the bot may take to reply
+	Stderr      io.Writer     // where the bot's standard error goes; nil discards it
+}
+
+// Bot implements kingdomstate.Strategy by asking an external program. It
+// plays one game at a time; run a Bot for each concurrent game.
+type Bot struct {
+	cfg Config
+
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	lines     chan []byte // the bot's output, closed when it ends
+	done      chan struct{}
+	forfeited bool
+
+	failures int
+	err      error
+}
+
+func New(cfg Config) *Bot {
+	return &Bot{cfg: cfg}
+}
+
+// Failures is the number of games the bot has forfeited.
+func (b *Bot) Failures() int {
+	return b.failures
+}
+
+// Err is why the bot last forfeited, or nil if it never has.
+func (b *Bot) Err() error {
+	return b.err
+}
+
+// Decide implements kingdomstate.Strategy.
+func (b *Bot) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	if ks.YearOfRule() == 0 {
+		b.forfeited = false
+	}
+	if b.forfeited {
+		return kingdomstate.Decision{}
+	}
+	d, err := b.decide(ks)
+	if err != nil {
+		b.failures++
+		b.err = err
+		b.forfeited = true
+		b.Close()
+		return kingdomstate.Decision{}
+	}
+	return d
+}
+
+func (b *Bot) decide(ks kingdomstate.KingdomState) (kingdomstate.Decision, error) {
+	if b.cmd == nil {
+		if err := b.start(); err != nil {
+			return kingdomstate.Decision{}, err
+		}
+	}
+	// The move timeout covers sending the kingdom as well as the reply, so a
+	// bot that stops reading cannot stall the game either
+	deadline := time.After(b.cfg.MoveTimeout)
+	if ks.YearOfRule() == 0 {
+		if err := b.send(Start{Type: "start", Rules: ks.Rules()}, deadline); err != nil {
+			return kingdomstate.Decision{}, err
+		}
+	}
+	if err := b.send(NewState(ks), deadline); err != nil {
+		return kingdomstate.Decision{}, err
+	}
+
+	var line []byte
+	select {
+	case l, ok := <-b.lines:
+		if !ok {
+			return kingdomstate.Decision{}, ErrExited
+		}
+		line = l
+	case <-deadline:
+		return kingdomstate.Decision{}, fmt.Errorf("%w: no reply in %v", ErrTimeout, b.cfg.MoveTimeout)
+	}
+
+	var d Decision
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return kingdomstate.Decision{}, fmt.Errorf("%w: %q: %v", ErrMalformed, line, err)
+	}
+	if dec.More() {
+		return kingdomstate.Decision{}, fmt.Errorf("%w: %q: more than one decision", ErrMalformed, line)
+	}
+	return kingdomstate.Decision{
+		AcresToBuy:   d.AcresToBuy,
+		AcresToSell:  d.AcresToSell,
+		GrainForFood: d.GrainForFood,
+		AcresToPlant: d.AcresToPlant,
+	}, nil
+}
+
+func (b *Bot) start() error {
+	if len(b.cfg.Command) == 0 {
+		return errors.New("no bot command")
+	}
+	cmd := exec.Command(b.cfg.Command[0], b.cfg.Command[1:]...)
+	cmd.Stderr = b.cfg.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	b.cmd, b.stdin = cmd, stdin
+	b.lines, b.done = make(chan []byte), make(chan struct{})
+	go read(stdout, b.lines, b.done)
+	return nil
+}
+
+// read sends each line of the bot's output to lines until the output ends
+// or done is closed.
+func read(r io.Reader, lines chan<- []byte, done <-chan struct{}) {
+	defer close(lines)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		select {
+		case lines <- line:
+		case <-done:
+			return
+		}
+	}
+}
+
+// send writes a message to the bot, giving up at the deadline. A write left
+// blocked ends when the bot is closed.
+func (b *Bot) send(msg interface{}, deadline <-chan time.Time) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	written := make(chan error, 1)
+	go func() {
+		_, err := b.stdin.Write(append(line, '\n'))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrExited, err)
+		}
+		return nil
+	case <-deadline:
+		return fmt.Errorf("%w: the bot read nothing in %v", ErrTimeout, b.cfg.MoveTimeout)
+	}
+}
+
+// Close stops the bot's program, if it is running.
+func (b *Bot) Close() {
+	if b.cmd == nil {
+		return
+	}
+	close(b.done)
+	b.stdin.Close()
+	b.cmd.Process.Kill()
+	b.cmd.Wait()
+	b.cmd = nil
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+// TestMain runs the test binary as a bot when GOMURABI_TEST_BOT names how it should behave.
+func TestMain(m *testing.M) {
+	if behaviour := os.Getenv("GOMURABI_TEST_BOT"); behaviour != "" {
+		testBot(behaviour)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testBot feeds everyone and plants what it can, misbehaving as asked in the third year.
+func testBot(behaviour string) {
+	var rules kingdomstate.Rules
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		var msg struct {
+			State
+			Rules kingdomstate.Rules `json:"rules"`
+		}
+		json.Unmarshal(in.Bytes(), &msg)
+		if msg.Type == "start" {
+			rules = msg.Rules
+			continue
+		}
+		if msg.Year == 2 {
+			switch behaviour {
+			case "slow":
+				time.Sleep(10 * time.Second)
+			case "garbage":
+				fmt.Println("plant everything")
+				continue
+			case "exit":
+				os.Exit(1)
+			}
+		}
+		d := Decision{GrainForFood: msg.Population * rules.GrainPerPerson}
+		if d.GrainForFood > msg.Grain {
+			d.GrainForFood = msg.Grain
+		}
+		d.AcresToPlant = (msg.Grain - d.GrainForFood) * rules.AcresPerBushel
+		if d.AcresToPlant > msg.Acreage {
+			d.AcresToPlant = msg.Acreage
+		}
+		if d.AcresToPlant > msg.Population*rules.AcresPerPerson {
+			d.AcresToPlant = msg.Population * rules.AcresPerPerson
+		}
+		json.NewEncoder(os.Stdout).Encode(d)
+	}
+}
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+// testCommand runs the test binary as a bot, which behaves as the environment says.
+var testCommand = []string{os.Args[0]}
+
+func seededGame(seed int64) kingdomstate.KingdomState {
+	var ks kingdomstate.KingdomState
+	ks.SetupSeededState(kingdomstate.DefaultRules(), seed)
+	return ks
+}
+
+func (s *S) TestPlaysLikeTheGoStrategy(c *C) {
+	os.Setenv("GOMURABI_TEST_BOT", "feed")
+	defer os.Unsetenv("GOMURABI_TEST_BOT")
+	b := New(Config{Command: testCommand, MoveTimeout: 5 * time.Second})
+	defer b.Close()
+	for seed := int64(0); seed < 3; seed++ {
+		ks, want := seededGame(seed), seededGame(seed)
+		kingdomstate.RunGame(&ks, b)
+		kingdomstate.RunGame(&want, kingdomstate.FeedThenPlantStrategy{})
+		h, _ := ks.History()
+		wantH, _ := want.History()
+		c.Check(h.Decisions(), DeepEquals, wantH.Decisions(), Commentf("seed %d", seed))
+	}
+	c.Check(b.Failures(), Equals, 0)
+	c.Check(b.Err(), IsNil)
+}
+
+func (s *S) TestForfeits(c *C) {
+	defer os.Unsetenv("GOMURABI_TEST_BOT")
+	for behaviour, want := range map[string]error{
+		"slow":    ErrTimeout,
+		"garbage": ErrMalformed,
+		"exit":    ErrExited,
+	} {
+		os.Setenv("GOMURABI_TEST_BOT", behaviour)
+		b := New(Config{Command: testCommand, MoveTimeout: 500 * time.Millisecond})
+		ks := seededGame(1)
+		kingdomstate.RunGame(&ks, b)
+
+		c.Check(b.Failures(), Equals, 1, Commentf(behaviour))
+		c.Check(errors.Is(b.Err(), want), Equals, true, Commentf("%s: %v", behaviour, b.Err()))
+		c.Check(ks.StillInOffice(), Equals, false)
+		c.Check(ks.GameOverReason(), Not(Equals), kingdomstate.TermFinished, Commentf(behaviour))
+		h, _ := ks.History()
+		c.Check(h.Years[2].Decision, Equals, kingdomstate.Decision{}, Commentf(behaviour))
+
+		// The bot is started again for the next game
+		ks = seededGame(2)
+		kingdomstate.RunGame(&ks, b)
+		b.Close()
+		c.Check(b.Failures(), Equals, 2, Commentf(behaviour))
+		h, _ = ks.History()
+		c.Check(h.Years[0].Decision, Not(Equals), kingdomstate.Decision{}, Commentf(behaviour))
+	}
+}
+
+func (s *S) TestSendTimesOut(c *C) {
+	// A bot that stops reading blocks writes to it once the pipe fills; here
+	// the pipe holds nothing at all
+	r, w := io.Pipe()
+	defer r.Close()
+	b := New(Config{MoveTimeout: 50 * time.Millisecond})
+	b.stdin = w
+	err := b.send(NewState(seededGame(1)), time.After(b.cfg.MoveTimeout))
+	c.Check(errors.Is(err, ErrTimeout), Equals, true, Commentf("%v", err))
+	w.Close()
+}
+
+func (s *S) TestMissingProgram(c *C) {
+	b := New(Config{Command: []string{"/nonexistent/bot"}, MoveTimeout: time.Second})
+	ks := seededGame(1)
+	kingdomstate.RunGame(&ks, b)
+	c.Check(b.Failures(), Equals, 1)
+	c.Check(b.Err(), NotNil)
+}
@@ -0,0 +1,257 @@
+// Package env wraps the game as an environment for reinforcement learning:
+// Reset starts an episode, a reign, and Step plays one year of it.
+package env
+
+import (
+	"errors"
+	"gomurabi/kingdomstate"
+	"math/rand"
+)
+
+// ErrNotReset is set in Info.Err by a step taken before the first Reset.
+var ErrNotReset = errors.New("step before the environment was reset")
+
+// Observation is what the agent sees at the start of each year.
+type Observation struct {
+	Year           uint // years of rule so far
+	Population     uint
+	Acreage        uint
+	Grain          uint
+	PricePerAcre   uint    // price of land this year
+	HarvestPerAcre uint    // last year's
+	RatPercent     uint    // last year's
+	Plague         bool    // last year's
+	PercentStarved float64 // average percent of the people starved each year so far
+
+	rules kingdomstate.Rules
+}
+
+// ObservationSize is the length of Observation.Vector.
+const ObservationSize = 9
+
+// Vector encodes the observation as numbers of around 0 to 1: the kingdom's
+// population, acreage and grain as shares of their initial values, the year
+// as a share of the term, the price, harvest and rats within their ranges,
+// and the plague and starvation as shares.
+func (o Observation) Vector() []float64 {
+	r := o.rules
+	plague := 0.0
+	if o.Plague {
+		plague = 1
+	}
+	return []float64{
+		ratio(o.Year, r.TermYears),
+		ratio(o.Population, r.InitialPopulation),
+		ratio(o.Acreage, r.InitialAcreage),
+		ratio(o.Grain, r.InitialGrain),
+		within(o.PricePerAcre, r.MinPricePerAcre, r.MaxPricePerAcre),
+		within(o.HarvestPerAcre, r.MinYieldPerAcre, r.MaxYieldPerAcre),
+		ratio(o.RatPercent, r.MaxRatPercent),
+		plague,
+		o.PercentStarved / 100,
+	}
+}
+
+func ratio(v, scale uint) float64 {
+	if scale == 0 {
+		return float64(v)
+	}
+	return float64(v) / float64(scale)
+}
+
+func within(v, lo, hi uint) float64 {
+	if hi <= lo {
+		return 0
+	}
+	return (float64(v) - float64(lo)) / float64(hi-lo)
+}
+
+// An Action is a decision as shares of what the kingdom could do. Every
+// share is clamped to its range.
+type Action struct {
+	Trade float64 // from -1 to 1: share of the acreage to sell if negative, or of the land the grain could buy if positive
+	Food  float64 // from 0 to 1: share of the people's needs to feed them
+	Plant float64 // from 0 to 1: share of the land that can be planted with the grain and people left
+}
+
+// Continuous makes an action from a vector of its three shares, each from -1
+// to 1, as policy-gradient agents produce; food and plant map from -1..1 to 0..1.
+func Continuous(v []float64) Action {
+	var a Action
+	if len(v) > 0 {
+		a.Trade = v[0]
+	}
+	if len(v) > 1 {
+		a.Food = (v[1] + 1) / 2
+	}
+	if len(v) > 2 {
+		a.Plant = (v[2] + 1) / 2
+	}
+	return a
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Decision turns the action into orders for the kingdom.
+func (a Action) Decision(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	rules := ks.Rules()
+	bid, ask := ks.NextYearBidPerAcre(), ks.NextYearAskPerAcre()
+	population := ks.Population()
+	grain := ks.Grain()
+	acreage := ks.Acreage()
+
+	var d kingdomstate.Decision
+	trade := clamp(a.Trade, -1, 1)
+	switch {
+	case trade > 0 && ask > 0:
+		d.AcresToBuy = uint(trade * float64(grain/ask))
+	case trade < 0:
+		d.AcresToSell = uint(-trade * float64(acreage))
+	}
+	grain = grain - d.AcresToBuy*ask + d.AcresToSell*bid
+	acreage = acreage + d.AcresToBuy - d.AcresToSell
+
+	d.GrainForFood = uint(clamp(a.Food, 0, 1) * float64(min(population*rules.GrainPerPerson, grain)))
+	grain -= d.GrainForFood
+	plantable := min(min(grain*rules.AcresPerBushel, acreage), ks.Workers()*rules.AcresPerPerson)
+	d.AcresToPlant = uint(clamp(a.Plant, 0, 1) * float64(plantable))
+	return d
+}
+
+func min(i, j uint) uint {
+	if i < j {
+		return i
+	}
+	return j
+}
+
+// DefaultActions is a table of discrete actions: trade up to a fifth of what
+// could be traded, feed everyone or nine tenths of the people, and plant all
+// that can be planted.
+func DefaultActions() []Action {
+	var actions []Action
+	for _, trade := range []float64{-0.2, -0.1, 0, 0.1, 0.2} {
+		for _, food := range []float64{1, 0.9} {
+			actions = append(actions, Action{Trade: trade, Food: food, Plant: 1})
+		}
+	}
+	return actions
+}
+
+// Rewards shape the reward of each step. The return of a whole episode with
+// the default is the final score of the reign.
+type Rewards struct {
+	PerYear        float64 // for each year the ruler stays in office
+	PerStarved     float64 // for each percent of the people starved in a year
+	Removed        float64 // for being thrown out of office or losing everyone
+	FinalScoreRate float64 // times the score of the reign, when it ends
+}
+
+func DefaultRewards() Rewards {
+	return Rewards{FinalScoreRate: 1}
+}
+
+// Info holds details of a step beyond the observation and reward.
+type Info struct {
+	Report kingdomstate.YearReport
+	Rating kingdomstate.Rating // once the episode is done
+	Err    error               // why the step could not be taken
+}
+
+// Env is a reinforcement learning environment around the game.
+type Env struct {
+	Rules   kingdomstate.Rules
+	Rewards Rewards
+	Actions []Action // discrete actions, by index
+
+	ks      kingdomstate.KingdomState
+	randgen *rand.Rand
+	reset   bool // whether an episode was ever started
+}
+
+func New(rules kingdomstate.Rules) *Env {
+	return &Env{
+		Rules:   rules,
+		Rewards: DefaultRewards(),
+		Actions: DefaultActions(),
+		randgen: rand.New(kingdomstate.NewSource(0)),
+	}
+}
+
+// Reset starts a new reign, with its random events seeded by seed.
+func (e *Env) Reset(seed int64) Observation {
+	e.randgen.Seed(seed)
+	e.ks.SetupInitialState(e.Rules, kingdomstate.NewRandomEvents(e.Rules, e.randgen))
+	e.reset = true
+	return e.observe()
+}
+
+// Step plays one year with the action, and reports the kingdom after it, the
+// reward for the year and whether the reign is over. Stepping before Reset,
+// or in an episode that is over, sets Info.Err.
+func (e *Env) Step(a Action) (Observation, float64, bool, Info) {
+	var info Info
+	if !e.reset {
+		info.Err = ErrNotReset
+		return e.observe(), 0, true, info
+	}
+	report, err := e.ks.ApplyDecision(a.Decision(e.ks))
+	if err != nil {
+		info.Err = err
+		return e.observe(), 0, true, info
+	}
+	info.Report = report
+
+	reward := e.Rewards.PerStarved * 100 * float64(report.StarvationVictims) / float64(report.StartOfYearPopulation)
+	reason := report.GameOverReason
+	if reason == kingdomstate.StillRuling || reason == kingdomstate.TermFinished {
+		reward += e.Rewards.PerYear
+	} else {
+		reward += e.Rewards.Removed
+	}
+	done := !report.StillInOffice
+	if done {
+		info.Rating = e.ks.Evaluate()
+		reward += e.Rewards.FinalScoreRate * info.Rating.Score()
+	}
+	return e.observe(), reward, done, info
+}
+
+// StepDiscrete steps with the i'th of the discrete actions.
+func (e *Env) StepDiscrete(i int) (Observation, float64, bool, Info) {
+	return e.Step(e.Actions[i])
+}
+
+// Kingdom returns a copy of the kingdom as it stands.
+func (e *Env) Kingdom() kingdomstate.KingdomState {
+	return e.ks
+}
+
+func (e *Env) observe() Observation {
+	return Observe(e.ks)
+}
+
+// Observe returns what an agent sees of the kingdom.
+func Observe(ks kingdomstate.KingdomState) Observation {
+	r := ks.LastReport()
+	return Observation{
+		Year:           ks.YearOfRule(),
+		Population:     ks.Population(),
+		Acreage:        ks.Acreage(),
+		Grain:          ks.Grain(),
+		PricePerAcre:   ks.NextYearPricePerAcre(),
+		HarvestPerAcre: r.HarvestPerAcre,
+		RatPercent:     r.PercentEatenByRats,
+		Plague:         r.PlagueHappened,
+		PercentStarved: ks.Evaluate().PercentStarved,
+		rules:          ks.Rules(),
+	}
+}
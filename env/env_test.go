@@ -0,0 +1,102 @@
+package env
+
+import (
+	"errors"
+	"gomurabi/kingdomstate"
+	"testing"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (s *S) TestReset(c *C) {
+	e := New(kingdomstate.DefaultRules())
+	o := e.Reset(5)
+	c.Check(o.Year, Equals, uint(0))
+	c.Check(o.Population, Equals, uint(100))
+	c.Check(o.HarvestPerAcre, Equals, uint(3))
+	c.Check(e.Reset(5), Equals, o)
+
+	v := o.Vector()
+	c.Assert(v, HasLen, ObservationSize)
+	c.Check(v[:4], DeepEquals, []float64{0, 1, 1, 1})
+	for _, x := range v {
+		c.Check(x >= 0 && x <= 1, Equals, true)
+	}
+}
+
+func (s *S) TestEpisode(c *C) {
+	e := New(kingdomstate.DefaultRules())
+
+	// There are no steps before the first reign starts
+	_, reward, done, info := e.Step(Action{Trade: 1, Food: 1, Plant: 1})
+	c.Check(info.Err, Equals, ErrNotReset)
+	c.Check(reward, Equals, 0.0)
+	c.Check(done, Equals, true)
+	_, _, _, info = e.StepDiscrete(0)
+	c.Check(info.Err, Equals, ErrNotReset)
+
+	e.Reset(9)
+	var total float64
+	done = false
+	for steps := 0; !done; steps++ {
+		var o Observation
+		var reward float64
+		o, reward, done, info = e.StepDiscrete(4) // no trade, feed everyone
+		c.Assert(info.Err, IsNil)
+		c.Check(o.Year, Equals, uint(steps+1))
+		c.Check(info.Report.Year, Equals, o.Year)
+		total += reward
+	}
+	c.Check(info.Rating, Equals, e.Kingdom().Evaluate())
+	c.Check(total, Equals, info.Rating.Score())
+
+	// Once the reign is over, there are no more steps
+	_, reward, done, info = e.Step(Action{})
+	c.Check(errors.Is(info.Err, kingdomstate.ErrGameOver), Equals, true)
+	c.Check(reward, Equals, 0.0)
+	c.Check(done, Equals, true)
+}
+
+func (s *S) TestRewards(c *C) {
+	e := New(kingdomstate.DefaultRules())
+	e.Rewards = Rewards{PerYear: 1, PerStarved: -0.1, Removed: -10}
+
+	e.Reset(1)
+	_, reward, done, _ := e.Step(Action{Food: 0.9, Plant: 1})
+	c.Check(done, Equals, false)
+	c.Check(reward, Equals, 1-0.1*10)
+
+	e.Reset(1)
+	_, reward, done, _ = e.Step(Action{Food: 0.5, Plant: 1})
+	c.Check(done, Equals, true)
+	c.Check(reward, Equals, -10-0.1*50)
+}
+
+func (s *S) TestActionDecision(c *C) {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupInitialState(kingdomstate.DefaultRules(), kingdomstate.FixedEvents{})
+	c.Check(Action{Food: 1, Plant: 1}.Decision(ks), Equals, kingdomstate.Decision{GrainForFood: 2000, AcresToPlant: 1000})
+	c.Check(Action{Trade: -0.5, Food: 1, Plant: 0.5}.Decision(ks), Equals,
+		kingdomstate.Decision{AcresToSell: 500, GrainForFood: 2000, AcresToPlant: 250})
+	c.Check(Action{Trade: 1}.Decision(ks), Equals, kingdomstate.Decision{AcresToBuy: 2800 / 21})
+
+	// Shares out of range are clamped
+	c.Check(Action{Trade: 5, Food: 2, Plant: 2}.Decision(ks), Equals, Action{Trade: 1, Food: 1, Plant: 1}.Decision(ks))
+	for _, a := range DefaultActions() {
+		c.Check(kingdomstate.ValidateDecision(ks, a.Decision(ks)), IsNil)
+	}
+}
+
+func (s *S) TestContinuous(c *C) {
+	c.Check(Continuous([]float64{-0.5, 1, -1}), Equals, Action{Trade: -0.5, Food: 1, Plant: 0})
+	c.Check(Continuous([]float64{0, 0, 0}), Equals, Action{Food: 0.5, Plant: 0.5})
+	c.Check(Continuous(nil), Equals, Action{})
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"gomurabi/evolve"
+	"gomurabi/kingdomstate"
+)
+
+func runEvolve(rules kingdomstate.Rules, threads, generations int, seed int64) {
+	cfg := evolve.DefaultConfig()
+	cfg.Rules = rules
+	cfg.Threads = threads
+	cfg.Generations = generations
+	cfg.Seed = seed
+
+	best, fitness := evolve.Evolve(cfg, func(g evolve.Generation) {
+		fmt.Printf("Generation %3d: best=%8.2f mean=%8.2f  %v\n", g.Number, g.BestFitness, g.MeanFitness, g.Best)
+	})
+	fmt.Printf("Best genome: %v\nFitness=%.2f\n", best, fitness)
+}
@@ -0,0 +1,139 @@
+// Package evolve searches for good strategies with a genetic algorithm.
+package evolve
+
+import (
+	"gomurabi/kingdomstate"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+type Config struct {
+	Rules          kingdomstate.Rules
+	PopulationSize int     // genomes per generation
+	Generations    int     // generations to evolve
+	GamesPerGenome int     // games played to measure each genome's fitness
+	TournamentSize int     // genomes competing to be picked as a parent
+	CrossoverRate  float64 // chance a child has two parents rather than one
+	MutationRate   float64 // chance each gene of a child is mutated
+	MutationScale  float64 // size of a mutation, as a share of the gene's range
+	Elitism        int     // best genomes copied unchanged into the next generation
+	Threads        int     // goroutines used to play games
+	Seed           int64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Rules:          kingdomstate.DefaultRules(),
+		PopulationSize: 50,
+		Generations:    30,
+		GamesPerGenome: 500,
+		TournamentSize: 3,
+		CrossoverRate:  0.7,
+		MutationRate:   0.2,
+		MutationScale:  0.1,
+		Elitism:        2,
+		Threads:        1,
+		Seed:           1,
+	}
+}
+
+// Generation summarizes one generation of the search.
+type Generation struct {
+	Number      int
+	Best        Genome
+	BestFitness float64
+	MeanFitness float64
+}
+
+// Fitness is the mean rating score of the genome over a number of games, as
+// measured by kingdomstate.MeanScore, so genomes measured with the same seed
+// face the same random events.
+func Fitness(g Genome, rules kingdomstate.Rules, games int, seed int64) float64 {
+	return kingdomstate.MeanScore(rules, g, games, seed)
+}
+
+// evaluate measures the fitness of every genome, sharing them out between threads.
+func evaluate(genomes []Genome, fitness []float64, rules kingdomstate.Rules, games, threads int, seed int64) {
+	var wg sync.WaitGroup
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			for i := t; i < len(genomes); i += threads {
+				fitness[i] = Fitness(genomes[i], rules, games, seed)
+			}
+			wg.Done()
+		}(t)
+	}
+	wg.Wait()
+}
+
+// tournament picks the fittest of a few randomly chosen genomes.
+func tournament(genomes []Genome, fitness []float64, size int, randgen *rand.Rand) Genome {
+	best := randgen.Intn(len(genomes))
+	for i := 1; i < size; i++ {
+		j := randgen.Intn(len(genomes))
+		if fitness[j] > fitness[best] {
+			best = j
+		}
+	}
+	return genomes[best]
+}
+
+// byFitness sorts genomes from fittest to least fit.
+type byFitness struct {
+	genomes []Genome
+	fitness []float64
+}
+
+func (s byFitness) Len() int           { return len(s.genomes) }
+func (s byFitness) Less(i, j int) bool { return s.fitness[i] > s.fitness[j] }
+func (s byFitness) Swap(i, j int) {
+	s.genomes[i], s.genomes[j] = s.genomes[j], s.genomes[i]
+	s.fitness[i], s.fitness[j] = s.fitness[j], s.fitness[i]
+}
+
+// Evolve runs the genetic algorithm, calling report (if not nil) after each
+// generation, and returns the fittest genome of the last generation. The
+// result depends only on the configuration, not on the number of threads.
+func Evolve(cfg Config, report func(Generation)) (Genome, float64) {
+	randgen := rand.New(rand.NewSource(cfg.Seed))
+	if cfg.Threads < 1 {
+		cfg.Threads = 1
+	}
+
+	genomes := make([]Genome, cfg.PopulationSize)
+	fitness := make([]float64, cfg.PopulationSize)
+	for i := range genomes {
+		genomes[i] = RandomGenome(randgen)
+	}
+
+	for gen := 1; ; gen++ {
+		evaluate(genomes, fitness, cfg.Rules, cfg.GamesPerGenome, cfg.Threads, kingdomstate.SubSeed(cfg.Seed, uint64(gen)))
+		sort.Stable(byFitness{genomes, fitness})
+
+		if report != nil {
+			var total float64
+			for _, f := range fitness {
+				total += f
+			}
+			report(Generation{gen, genomes[0], fitness[0], total / float64(len(fitness))})
+		}
+		if gen >= cfg.Generations {
+			return genomes[0], fitness[0]
+		}
+
+		next := make([]Genome, 0, cfg.PopulationSize)
+		for i := 0; i < cfg.Elitism && i < len(genomes); i++ {
+			next = append(next, genomes[i])
+		}
+		for len(next) < cfg.PopulationSize {
+			child := tournament(genomes, fitness, cfg.TournamentSize, randgen)
+			if randgen.Float64() < cfg.CrossoverRate {
+				child = Crossover(child, tournament(genomes, fitness, cfg.TournamentSize, randgen), randgen)
+			}
+			next = append(next, Mutate(child, cfg.MutationRate, cfg.MutationScale, randgen))
+		}
+		genomes = next
+	}
+}
@@ -0,0 +1,116 @@
+package evolve
+
+import (
+	. "github.com/go-check/check"
+	"gomurabi/kingdomstate"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+var randgen *rand.Rand
+
+func (s *S) SetUpTest(c *C) {
+	randgen = rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+func inRange(g Genome) bool {
+	for i, v := range g.genes() {
+		if v < geneRanges[i][0] || v > geneRanges[i][1] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *S) TestGenes(c *C) {
+	g := Genome{1.1, 0.9, 0.2, 0.8, 0.5}
+	c.Check(fromGenes(g.genes()), Equals, g)
+}
+
+func (s *S) TestOperatorsStayInRange(c *C) {
+	for i := 0; i < 1000; i++ {
+		a := RandomGenome(randgen)
+		b := RandomGenome(randgen)
+		c.Check(inRange(a), Equals, true)
+		c.Check(inRange(Crossover(a, b, randgen)), Equals, true)
+		c.Check(inRange(Mutate(a, 1, 1, randgen)), Equals, true)
+	}
+}
+
+func (s *S) TestCrossoverTakesParentGenes(c *C) {
+	a := RandomGenome(randgen)
+	b := RandomGenome(randgen)
+	child := Crossover(a, b, randgen).genes()
+	for i, v := range child {
+		c.Check(v == a.genes()[i] || v == b.genes()[i], Equals, true)
+	}
+}
+
+func (s *S) TestDecideStaysWithinMeans(c *C) {
+	for i := 0; i < 1000; i++ {
+		var ks kingdomstate.KingdomState
+		g := RandomGenome(randgen)
+
+		ks.SetupInitialState(kingdomstate.DefaultRules(), kingdomstate.NewRandomEvents(kingdomstate.DefaultRules(), randgen))
+		for ks.StillInOffice() {
+			d := g.Decide(ks)
+			price := ks.NextYearPricePerAcre()
+			c.Assert(d.AcresToBuy*price <= ks.Grain(), Equals, true)
+			c.Assert(d.AcresToSell <= ks.Acreage()+d.AcresToBuy, Equals, true)
+			grain := ks.Grain() - d.AcresToBuy*price + d.AcresToSell*price
+			c.Assert(d.GrainForFood+d.AcresToPlant/kingdomstate.AcresPerBushel <= grain, Equals, true)
+			ks.TallyUpYear(d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant)
+		}
+	}
+}
+
+func (s *S) TestPriceGenesFollowRules(c *C) {
+	rules := kingdomstate.DefaultRules()
+	rules.MinPricePerAcre, rules.MaxPricePerAcre = 1000, 2000
+	var ks kingdomstate.KingdomState
+	ks.SetupInitialState(rules, kingdomstate.NewRandomEvents(rules, randgen))
+
+	always := Genome{FeedRatio: 1, PlantRatio: 1, BuyBelow: 1.25, SellAbove: 1.25, TradeRatio: 1}
+	c.Check(always.Decide(ks).AcresToBuy > 0, Equals, true)
+	never := Genome{FeedRatio: 1, PlantRatio: 1, BuyBelow: -0.25, SellAbove: 1.25, TradeRatio: 1}
+	c.Check(never.Decide(ks).AcresToBuy, Equals, uint(0))
+	c.Check(never.Decide(ks).AcresToSell, Equals, uint(0))
+	sell := Genome{FeedRatio: 1, PlantRatio: 1, BuyBelow: -0.25, SellAbove: -0.25, TradeRatio: 1}
+	c.Check(sell.Decide(ks).AcresToSell > 0, Equals, true)
+}
+
+func (s *S) TestFitness(c *C) {
+	g := RandomGenome(randgen)
+	rules := kingdomstate.DefaultRules()
+	c.Check(Fitness(g, rules, 50, 8), Equals, Fitness(g, rules, 50, 8))
+}
+
+func (s *S) TestEvolve(c *C) {
+	cfg := DefaultConfig()
+	cfg.PopulationSize = 10
+	cfg.Generations = 3
+	cfg.GamesPerGenome = 20
+	cfg.Threads = 2
+
+	var generations []Generation
+	best, fitness := Evolve(cfg, func(g Generation) { generations = append(generations, g) })
+	c.Assert(generations, HasLen, 3)
+	c.Check(generations[2].Best, Equals, best)
+	c.Check(generations[2].BestFitness, Equals, fitness)
+	c.Check(inRange(best), Equals, true)
+	for _, g := range generations {
+		c.Check(g.BestFitness >= g.MeanFitness, Equals, true)
+	}
+
+	// The same seed gives the same result with any number of threads
+	cfg.Threads = 3
+	again, _ := Evolve(cfg, nil)
+	c.Check(again, Equals, best)
+}
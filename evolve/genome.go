@@ -0,0 +1,115 @@
+package evolve
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"math/rand"
+)
+
+// A Genome is a parameterized strategy for ruling the kingdom.
+type Genome struct {
+	FeedRatio  float64 // share of the people's food needs to feed them
+	PlantRatio float64 // share of the plantable land to plant
+	BuyBelow   float64 // buy land when the price is at or below this share of the way from the rules' lowest price to their highest
+	SellAbove  float64 // sell land when the price is at or above this share of the way
+	TradeRatio float64 // share of the possible trade to make when buying or selling
+}
+
+const numGenes = 5
+
+// Allowed range of each gene, in the order returned by genes. The price genes
+// reach a little past the rules' price range, so that a genome may also never
+// or always trade.
+var geneRanges = [numGenes][2]float64{
+	{0, 1.5},
+	{0, 1},
+	{-0.25, 1.25},
+	{-0.25, 1.25},
+	{0, 1},
+}
+
+func (g Genome) genes() [numGenes]float64 {
+	return [numGenes]float64{g.FeedRatio, g.PlantRatio, g.BuyBelow, g.SellAbove, g.TradeRatio}
+}
+
+func fromGenes(genes [numGenes]float64) Genome {
+	return Genome{genes[0], genes[1], genes[2], genes[3], genes[4]}
+}
+
+func (g Genome) String() string {
+	return fmt.Sprintf("feed=%.3f plant=%.3f buy<=%.3f sell>=%.3f trade=%.3f",
+		g.FeedRatio, g.PlantRatio, g.BuyBelow, g.SellAbove, g.TradeRatio)
+}
+
+// RandomGenome returns a genome with every gene drawn uniformly from its range.
+func RandomGenome(randgen *rand.Rand) Genome {
+	var genes [numGenes]float64
+	for i, r := range geneRanges {
+		genes[i] = r[0] + randgen.Float64()*(r[1]-r[0])
+	}
+	return fromGenes(genes)
+}
+
+// Crossover takes each gene from one parent or the other at random.
+func Crossover(a, b Genome, randgen *rand.Rand) Genome {
+	genes, other := a.genes(), b.genes()
+	for i := range genes {
+		if randgen.Intn(2) == 1 {
+			genes[i] = other[i]
+		}
+	}
+	return fromGenes(genes)
+}
+
+// Mutate nudges each gene, with probability rate, by a normally distributed
+// amount whose standard deviation is scale times the gene's range.
+func Mutate(g Genome, rate, scale float64, randgen *rand.Rand) Genome {
+	genes := g.genes()
+	for i, r := range geneRanges {
+		if randgen.Float64() >= rate {
+			continue
+		}
+		genes[i] += randgen.NormFloat64() * scale * (r[1] - r[0])
+		if genes[i] < r[0] {
+			genes[i] = r[0]
+		}
+		if genes[i] > r[1] {
+			genes[i] = r[1]
+		}
+	}
+	return fromGenes(genes)
+}
+
+// Decide implements kingdomstate.Strategy.
+func (g Genome) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	price := ks.NextYearPricePerAcre()
+	bid, ask := ks.NextYearBidPerAcre(), ks.NextYearAskPerAcre()
+	population := ks.Population()
+	grain := ks.Grain()
+	acreage := ks.Acreage()
+	rules := ks.Rules()
+	workable := ks.Workers() * rules.AcresPerPerson
+	lowest, spread := float64(rules.MinPricePerAcre), float64(rules.MaxPricePerAcre-rules.MinPricePerAcre)
+
+	var d kingdomstate.Decision
+	switch {
+	case float64(price) <= lowest+g.BuyBelow*spread && acreage < workable:
+		d.AcresToBuy = uint(g.TradeRatio * float64(min(workable-acreage, grain/ask)))
+	case float64(price) >= lowest+g.SellAbove*spread:
+		d.AcresToSell = uint(g.TradeRatio * float64(acreage))
+	}
+	grain = grain - d.AcresToBuy*ask + d.AcresToSell*bid
+	acreage = acreage + d.AcresToBuy - d.AcresToSell
+
+	d.GrainForFood = min(uint(g.FeedRatio*float64(population*rules.GrainPerPerson)), grain)
+	grain -= d.GrainForFood
+	d.AcresToPlant = uint(g.PlantRatio * float64(min(min(grain*rules.AcresPerBushel, acreage), workable)))
+	return d
+}
+
+func min(i, j uint) uint {
+	if i < j {
+		return i
+	}
+	return j
+}
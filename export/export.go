@@ -0,0 +1,147 @@
+// Package export writes per-year game records in machine-readable formats.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"io"
+	"strconv"
+)
+
+// Record is one year of one game. The JSON names double as the CSV header.
+type Record struct {
+	Game               uint64 `json:"game"`
+	Seed               int64  `json:"seed"`
+	Year               uint   `json:"year"`
+	Population         uint   `json:"population"`
+	Acreage            uint   `json:"acreage"`
+	Grain              uint   `json:"grain"`
+	Price              uint   `json:"price"`
+	HarvestPerAcre     uint   `json:"harvest_per_acre"`
+	PercentEatenByRats uint   `json:"percent_eaten_by_rats"`
+	Plague             bool   `json:"plague"`
+	StarvationVictims  uint   `json:"starvation_victims"`
+	PlagueVictims      uint   `json:"plague_victims"`
+	Immigrants         uint   `json:"immigrants"`
+	AcresToBuy         uint   `json:"acres_to_buy"`
+	AcresToSell        uint   `json:"acres_to_sell"`
+	GrainForFood       uint   `json:"grain_for_food"`
+	AcresToPlant       uint   `json:"acres_to_plant"`
+	StillInOffice      bool   `json:"still_in_office"`
+}
+
+var Columns = []string{
+	"game", "seed", "year", "population", "acreage", "grain", "price",
+	"harvest_per_acre", "percent_eaten_by_rats", "plague",
+	"starvation_victims", "plague_victims", "immigrants",
+	"acres_to_buy", "acres_to_sell", "grain_for_food", "acres_to_plant",
+	"still_in_office",
+}
+
+// NewRecord makes a record of a year of rule. Population, acreage and grain
+// are as at the end of the year; price is what land traded at during it.
+func NewRecord(game uint64, seed int64, r kingdomstate.YearReport) Record {
+	return Record{
+		Game:               game,
+		Seed:               seed,
+		Year:               r.Year,
+		Population:         r.EndOfYearPopulation,
+		Acreage:            r.EndOfYearAcreage,
+		Grain:              r.EndOfYearGrain,
+		Price:              r.PricePerAcre,
+		HarvestPerAcre:     r.HarvestPerAcre,
+		PercentEatenByRats: r.PercentEatenByRats,
+		Plague:             r.PlagueHappened,
+		StarvationVictims:  r.StarvationVictims,
+		PlagueVictims:      r.PlagueVictims,
+		Immigrants:         r.Immigrants,
+		AcresToBuy:         r.Decision.AcresToBuy,
+		AcresToSell:        r.Decision.AcresToSell,
+		GrainForFood:       r.Decision.GrainForFood,
+		AcresToPlant:       r.Decision.AcresToPlant,
+		StillInOffice:      r.StillInOffice,
+	}
+}
+
+// Records makes a record of every year of rule in a game history.
+func Records(game uint64, h kingdomstate.GameHistory) []Record {
+	records := make([]Record, len(h.Years))
+	for i, r := range h.Years {
+		records[i] = NewRecord(game, h.Seed, r)
+	}
+	return records
+}
+
+func (r Record) fields() []string {
+	u := func(v uint) string { return strconv.FormatUint(uint64(v), 10) }
+	return []string{
+		strconv.FormatUint(r.Game, 10), strconv.FormatInt(r.Seed, 10), u(r.Year),
+		u(r.Population), u(r.Acreage), u(r.Grain), u(r.Price),
+		u(r.HarvestPerAcre), u(r.PercentEatenByRats), strconv.FormatBool(r.Plague),
+		u(r.StarvationVictims), u(r.PlagueVictims), u(r.Immigrants),
+		u(r.AcresToBuy), u(r.AcresToSell), u(r.GrainForFood), u(r.AcresToPlant),
+		strconv.FormatBool(r.StillInOffice),
+	}
+}
+
+// A Writer writes records. Flush must be called once all have been written.
+type Writer interface {
+	Write(r Record) error
+	Flush() error
+}
+
+// Formats understood by NewWriter.
+var Formats = []string{"jsonl", "csv"}
+
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case "jsonl":
+		buf := bufio.NewWriter(w)
+		return &jsonLinesWriter{buf, json.NewEncoder(buf)}, nil
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type jsonLinesWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *jsonLinesWriter) Write(r Record) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonLinesWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(r Record) error {
+	if !w.headerWritten {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	return w.w.Write(r.fields())
+}
+
+func (w *csvWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	. "github.com/go-check/check"
+	"gomurabi/kingdomstate"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func history() kingdomstate.GameHistory {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupSeededState(kingdomstate.DefaultRules(), 11)
+	kingdomstate.RunGame(&ks, kingdomstate.FeedThenPlantStrategy{})
+	h, _ := ks.History()
+	return h
+}
+
+func (s *S) TestColumnsMatchJSONNames(c *C) {
+	t := reflect.TypeOf(Record{})
+	c.Assert(t.NumField(), Equals, len(Columns))
+	for i := 0; i < t.NumField(); i++ {
+		c.Check(t.Field(i).Tag.Get("json"), Equals, Columns[i])
+	}
+	c.Check(Record{}.fields(), HasLen, len(Columns))
+}
+
+func (s *S) TestRecords(c *C) {
+	h := history()
+	records := Records(3, h)
+	c.Assert(records, HasLen, len(h.Years))
+	for i, r := range records {
+		c.Check(r.Game, Equals, uint64(3))
+		c.Check(r.Seed, Equals, int64(11))
+		c.Check(r.Year, Equals, uint(i+1))
+		c.Check(r.Population, Equals, h.Years[i].EndOfYearPopulation)
+		c.Check(r.GrainForFood, Equals, h.Years[i].Decision.GrainForFood)
+	}
+}
+
+func (s *S) TestJSONLines(c *C) {
+	var buf bytes.Buffer
+	records := Records(1, history())
+
+	w, err := NewWriter(&buf, "jsonl")
+	c.Assert(err, IsNil)
+	for _, r := range records {
+		c.Assert(w.Write(r), IsNil)
+	}
+	c.Assert(w.Flush(), IsNil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	c.Assert(lines, HasLen, len(records))
+	for i, line := range lines {
+		var r Record
+		c.Assert(json.Unmarshal([]byte(line), &r), IsNil)
+		c.Check(r, Equals, records[i])
+	}
+}
+
+func (s *S) TestCSV(c *C) {
+	var buf bytes.Buffer
+	records := Records(1, history())
+
+	w, err := NewWriter(&buf, "csv")
+	c.Assert(err, IsNil)
+	for _, r := range records {
+		c.Assert(w.Write(r), IsNil)
+	}
+	c.Assert(w.Flush(), IsNil)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, len(records)+1)
+	c.Check(rows[0], DeepEquals, Columns)
+	for i, r := range records {
+		c.Check(rows[i+1], DeepEquals, r.fields())
+	}
+}
+
+func (s *S) TestUnknownFormat(c *C) {
+	_, err := NewWriter(&bytes.Buffer{}, "xml")
+	c.Check(err, NotNil)
+}
@@ -2,44 +2,266 @@ package main
 
 import (
 	"fmt"
+	"gomurabi/bot"
+	"gomurabi/export"
 	"gomurabi/kingdomstate"
+	"gomurabi/mcts"
+	"gomurabi/qlearn"
+	"gomurabi/solver"
+	"gomurabi/stats"
 	"math/rand"
 	"time"
 	"runtime"
 	"flag"
+	"os"
+	"strings"
 	"sync"
 )
 
-func doit(wg *sync.WaitGroup, n int, randgen *rand.Rand) {	
-	for i := 0; i < n; i++ {
+// doit plays n games, numbered from first, collecting their outcomes and
+// sending the records of each to out if it is not nil. Game i is seeded with
+// kingdomstate.SubSeed(seed, i), so it plays out the same whichever thread
+// plays it.
+func doit(wg *sync.WaitGroup, rules kingdomstate.Rules, seed int64, first uint64, n uint64, strategy kingdomstate.Strategy, collector *stats.Collector, out chan<- []export.Record) {	
+	randgen := rand.New(kingdomstate.NewSource(seed))
+	events := kingdomstate.NewRandomEvents(rules, randgen)
+	for i := first; i < first+n; i++ {
 		var ks kingdomstate.KingdomState
 
-		ks.SetupInitialState(randgen)
-		for ks.StillInOffice() {
-			//ks.PrintSummary()
-			ks.TallyUpYear(0, 50, 2000, 10)
+		gameSeed := kingdomstate.SubSeed(seed, i)
+		if out == nil {
+			randgen.Seed(gameSeed)
+			ks.SetupInitialState(rules, events)
+			kingdomstate.RunGame(&ks, strategy)
+			collector.Add(stats.OutcomeOf(ks))
+			continue
 		}
-
+		ks.SetupSeededState(rules, gameSeed)
+		kingdomstate.RunGame(&ks, strategy)
+		collector.Add(stats.OutcomeOf(ks))
+		h, _ := ks.History()
+		out <- export.Records(i, h)
 	}
 	//fmt.Printf("Done %d\n", p)
 	wg.Done()
 }
 
+// writeRecords writes the records of each game as they arrive, and sends the first error, if any, to done.
+func writeRecords(w export.Writer, in <-chan []export.Record, done chan<- error) {
+	var err error
+	for records := range in {
+		for _, r := range records {
+			if err == nil {
+				err = w.Write(r)
+			}
+		}
+	}
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	done <- err
+}
+
 func main() {
 	var parthreads int
+	var interactive bool
+	var strategyName string
+	var evolving bool
+	var generations int
+	var historyFile, replayFile string
+	var games uint64
+	var outFile, format string
+	var seed int64
+	var rulesFile string
+	var solving bool
+	var iterations int
+	var trainQFile, playQFile string
+	var episodes int
+	var policyFile string
+	var serveAddr string
+	var gameTTL time.Duration
+	var maxGames int
+	var botCommand string
+	var moveTimeout time.Duration
+	var tournamentNames string
 	flag.IntVar(&parthreads, "threads", 1, "# of threads to use")
+	flag.BoolVar(&interactive, "play", false, "play an interactive game")
+	flag.Uint64Var(&games, "games", 10000000, "# of games to simulate, or of games each strategy plays in a -tournament (default 10000 there)")
+	flag.StringVar(&outFile, "out", "", "write a record of every year of every game to this file")
+	flag.StringVar(&format, "format", "jsonl", "format of -out: "+strings.Join(export.Formats, ", "))
+	flag.Int64Var(&seed, "seed", time.Now().UnixNano(), "seed from which every game's random events are derived")
+	flag.StringVar(&rulesFile, "rules", "", "JSON file of game rules (default: the classic rules)")
+	flag.StringVar(&historyFile, "history", "", "save the history of an interactive game to this file")
+	flag.StringVar(&replayFile, "replay", "", "replay a saved game history")
+	flag.BoolVar(&evolving, "evolve", false, "evolve a strategy with a genetic algorithm")
+	flag.IntVar(&generations, "generations", 30, "# of generations to evolve")
+	flag.StringVar(&strategyName, "strategy", "fixed", "strategy to simulate: "+strings.Join(strategyNames(), ", "))
+	flag.IntVar(&iterations, "iterations", mcts.DefaultConfig().Iterations, "search iterations per decision of the mcts strategy")
+	flag.StringVar(&trainQFile, "train-q", "", "train a Q-learning agent and save its Q-table to this file")
+	flag.StringVar(&playQFile, "play-q", "", "Q-table file of an agent to simulate instead of -strategy")
+	flag.IntVar(&episodes, "episodes", qlearn.DefaultConfig().Episodes, "# of episodes to train with -train-q")
+	flag.BoolVar(&solving, "solve", false, "solve for the optimal policy by dynamic programming, saving it to -policy")
+	flag.StringVar(&policyFile, "policy", "", "policy file to simulate instead of -strategy, or to save with -solve")
+	flag.StringVar(&serveAddr, "serve", "", "host games over an HTTP JSON API at this address, e.g. :8080")
+	flag.DurationVar(&gameTTL, "game-ttl", time.Hour, "how long -serve keeps a game nobody plays, or 0 for ever")
+	flag.IntVar(&maxGames, "max-games", 10000, "most games -serve keeps at once, or 0 for no limit")
+	flag.StringVar(&botCommand, "bot", "", "command of an external bot to simulate instead of -strategy, one per thread")
+	flag.DurationVar(&moveTimeout, "move-timeout", time.Second, "longest a -bot may take over a move before forfeiting")
+	flag.StringVar(&tournamentNames, "tournament", "", "comma-separated strategies, or \"all\", to rank on the same random futures; -policy and -play-q join as \"policy\" and \"q-learning\"")
 	flag.Parse()
+
+	rules := kingdomstate.DefaultRules()
+	if rulesFile != "" {
+		var err error
+		if rules, err = kingdomstate.LoadRules(rulesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load rules: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if interactive {
+		if err := play(rules, seed, historyFile); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	if replayFile != "" {
+		if err := replay(replayFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot replay %s: %v\n", replayFile, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if serveAddr != "" {
+		runServe(rules, serveAddr, gameTTL, maxGames)
+		return
+	}
+	if evolving {
+		runtime.GOMAXPROCS(parthreads)
+		runEvolve(rules, parthreads, generations, seed)
+		return
+	}
+
+	if trainQFile != "" {
+		runTrainQ(rules, episodes, seed, trainQFile)
+		return
+	}
+	if solving {
+		runtime.GOMAXPROCS(parthreads)
+		runSolve(rules, parthreads, policyFile)
+		return
+	}
+
+	searchConfig := mcts.DefaultConfig()
+	searchConfig.Iterations = iterations
+	strategies["mcts"] = mcts.Player{Config: searchConfig}
+
+	strategy, ok := strategies[strategyName]
+	if policyFile != "" {
+		policy, err := solver.LoadPolicy(policyFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load policy: %v\n", err)
+			os.Exit(1)
+		}
+		if policy.Rules() != rules {
+			fmt.Fprintf(os.Stderr, "Policy %s was solved for other rules\n", policyFile)
+			os.Exit(2)
+		}
+		strategies["policy"] = policy
+		strategy, ok = policy, true
+	}
+	if playQFile != "" {
+		agent, err := qlearn.Load(playQFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load Q-table: %v\n", err)
+			os.Exit(1)
+		}
+		if agent.Rules() != rules {
+			fmt.Fprintf(os.Stderr, "Q-table %s was trained under other rules\n", playQFile)
+			os.Exit(2)
+		}
+		strategies["q-learning"] = agent
+		strategy, ok = agent, true
+	}
+	if tournamentNames != "" {
+		if !flagSet("games") {
+			games = tournamentGames
+		}
+		runtime.GOMAXPROCS(parthreads)
+		runTournament(rules, tournamentNames, games, seed, parthreads)
+		return
+	}
+	var bots []*bot.Bot
+	if botCommand != "" {
+		for i := 0; i < parthreads; i++ {
+			bots = append(bots, bot.New(bot.Config{Command: strings.Fields(botCommand), MoveTimeout: moveTimeout, Stderr: os.Stderr}))
+		}
+		ok = true
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown strategy %q\n", strategyName)
+		os.Exit(2)
+	}
 	
 	runtime.GOMAXPROCS(parthreads)	
-	fmt.Printf("CPUs=%d\nThreads=%d\n", runtime.NumCPU(), parthreads)
+	fmt.Printf("CPUs=%d\nThreads=%d\nSeed=%d\n", runtime.NumCPU(), parthreads, seed)
 	
+	var out chan []export.Record
+	var writeDone chan error
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot create %s: %v\n", outFile, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w, err := export.NewWriter(f, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		out = make(chan []export.Record, 100)
+		writeDone = make(chan error)
+		go writeRecords(w, out, writeDone)
+	}
+
 	var wg sync.WaitGroup
-	n := 10000000/parthreads
+	collectors := make([]*stats.Collector, parthreads)
 	for i:=0; i<parthreads; i++ {
-		randgen := rand.New(rand.NewSource(time.Now().UnixNano()))
+		collectors[i] = stats.NewCollector()
+		first := uint64(i) * games / uint64(parthreads)
+		last := uint64(i+1) * games / uint64(parthreads)
+		s := strategy
+		if bots != nil {
+			s = bots[i]
+		}
 		wg.Add(1)
-		go doit(&wg, n, randgen)
+		go doit(&wg, rules, seed, first, last-first, s, collectors[i], out)
 	}
 	wg.Wait()
+	failures := 0
+	for _, b := range bots {
+		b.Close()
+		failures += b.Failures()
+		if b.Err() != nil {
+			fmt.Fprintf(os.Stderr, "Bot forfeited: %v\n", b.Err())
+		}
+	}
+	if out != nil {
+		close(out)
+		if err := <-writeDone; err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot write %s: %v\n", outFile, err)
+			os.Exit(1)
+		}
+	}
 	fmt.Printf("Done\n")
+	if bots != nil {
+		fmt.Printf("Bot forfeits=%d\n", failures)
+	}
+
+	total := stats.NewCollector()
+	for _, c := range collectors {
+		total.Merge(c)
+	}
+	total.Print(os.Stdout)
 }
@@ -0,0 +1,98 @@
+package kingdomstate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Decision holds the ruler's orders for one year of rule.
+type Decision struct {
+	AcresToBuy   uint
+	AcresToSell  uint
+	GrainForFood uint
+	AcresToPlant uint
+}
+
+var (
+	ErrNotEnoughGrain     = errors.New("not enough grain")
+	ErrNotEnoughLand      = errors.New("not enough land")
+	ErrNotEnoughPeople    = errors.New("not enough people to tend the fields")
+	ErrBuyAndSellSameYear = errors.New("cannot buy and sell land in the same year")
+)
+
+// DecisionMode controls what ApplyDecision does with orders that cannot be carried out.
+type DecisionMode int
+
+const (
+	Lenient DecisionMode = iota // carry out as much of each order as possible
+	Strict                      // refuse the whole decision
+)
+
+// ValidateDecision checks that every order in the decision can be carried
+// out in full, in the order TallyUpYear carries them out.
+func ValidateDecision(ks KingdomState, d Decision) error {
+	bid, ask := ks.bidAsk(ks.nextYearPricePerAcre)
+	grain := ks.grain
+	acreage := ks.acreage
+
+	if d.AcresToBuy > 0 && d.AcresToSell > 0 {
+		return ErrBuyAndSellSameYear
+	}
+
+	// Buy land, comparing acres rather than their cost, which may overflow
+	if ask == 0 || d.AcresToBuy > grain/ask {
+		return fmt.Errorf("%w: %d acres at %d bushels each cost more than the %d there are",
+			ErrNotEnoughGrain, d.AcresToBuy, ask, grain)
+	}
+	grain -= d.AcresToBuy * ask
+	acreage += d.AcresToBuy
+
+	// Sell land
+	if d.AcresToSell > acreage {
+		return fmt.Errorf("%w: cannot sell %d acres out of %d", ErrNotEnoughLand, d.AcresToSell, acreage)
+	}
+	grain += d.AcresToSell * bid
+	acreage -= d.AcresToSell
+
+	// Feed the people
+	if d.GrainForFood > grain {
+		return fmt.Errorf("%w: cannot feed %d bushels out of %d", ErrNotEnoughGrain, d.GrainForFood, grain)
+	}
+	grain -= d.GrainForFood
+
+	// Plant the fields
+	if d.AcresToPlant > acreage {
+		return fmt.Errorf("%w: cannot plant %d acres out of %d", ErrNotEnoughLand, d.AcresToPlant, acreage)
+	}
+	if workers := ks.Workers(); d.AcresToPlant > workers*ks.rules.AcresPerPerson {
+		return fmt.Errorf("%w: %d workers can plant only %d acres",
+			ErrNotEnoughPeople, workers, workers*ks.rules.AcresPerPerson)
+	}
+	if d.AcresToPlant/ks.rules.AcresPerBushel > grain {
+		return fmt.Errorf("%w: %d acres need %d bushels of seed but there are only %d",
+			ErrNotEnoughGrain, d.AcresToPlant, d.AcresToPlant/ks.rules.AcresPerBushel, grain)
+	}
+	return nil
+}
+
+func (ks *KingdomState) SetDecisionMode(mode DecisionMode) {
+	ks.decisionMode = mode
+}
+
+func (ks KingdomState) DecisionMode() DecisionMode {
+	return ks.decisionMode
+}
+
+// ApplyDecision plays out one year of rule. In Strict mode a decision that
+// fails ValidateDecision is refused and the kingdom is left untouched; in
+// Lenient mode each order is clamped to what can be done, and the report
+// shows how much of it was carried out. Either way it returns ErrGameOver if
+// the ruler is no longer in office.
+func (ks *KingdomState) ApplyDecision(d Decision) (YearReport, error) {
+	if ks.decisionMode == Strict && ks.stillInOffice {
+		if err := ValidateDecision(*ks, d); err != nil {
+			return YearReport{}, err
+		}
+	}
+	return ks.tally(d)
+}
@@ -0,0 +1,90 @@
+package kingdomstate
+
+import (
+	"errors"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestValidateDecision(c *C) {
+	var ks KingdomState
+
+	// 100 people, 1000 acres, 2800 bushels, land at 21 bushels per acre
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	tests := []struct {
+		d   Decision
+		err error
+	}{
+		{Decision{}, nil},
+		{Decision{AcresToBuy: 20, GrainForFood: 2000, AcresToPlant: 1020}, ErrNotEnoughGrain},
+		{Decision{AcresToBuy: 133}, nil},
+		{Decision{AcresToBuy: 134}, ErrNotEnoughGrain},
+		{Decision{AcresToSell: 1000, GrainForFood: 23800}, nil},
+		{Decision{AcresToSell: 1001}, ErrNotEnoughLand},
+		{Decision{AcresToBuy: 1, AcresToSell: 1}, ErrBuyAndSellSameYear},
+		{Decision{GrainForFood: 2801}, ErrNotEnoughGrain},
+		{Decision{GrainForFood: 2000, AcresToPlant: 1000}, nil},
+		{Decision{GrainForFood: 2000, AcresToPlant: 1002}, ErrNotEnoughLand},
+		{Decision{GrainForFood: 2301, AcresToPlant: 1000}, ErrNotEnoughGrain},
+		{Decision{AcresToBuy: 100, AcresToPlant: 1100}, nil},
+		{Decision{AcresToBuy: 1001, AcresToSell: 0, AcresToPlant: 2001}, ErrNotEnoughGrain},
+	}
+	for _, t := range tests {
+		err := ValidateDecision(ks, t.d)
+		c.Check(errors.Is(err, t.err), Equals, true, Commentf("%+v: %v", t.d, err))
+	}
+
+	ks.population = 10
+	err := ValidateDecision(ks, Decision{AcresToPlant: 201})
+	c.Check(errors.Is(err, ErrNotEnoughPeople), Equals, true)
+	c.Check(ValidateDecision(ks, Decision{AcresToPlant: 200}), IsNil)
+}
+
+func (s *S) TestApplyDecisionLenient(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	c.Check(ks.DecisionMode(), Equals, Lenient)
+	d := Decision{AcresToBuy: 10000, GrainForFood: 2000, AcresToPlant: 1000}
+	report, err := ks.ApplyDecision(d)
+	c.Assert(err, IsNil)
+	c.Check(report.Year, Equals, uint(1))
+	c.Check(report.Decision, Equals, d)
+	c.Check(report.AcresBought, Equals, uint(2800/21))
+	c.Check(report.GrainFedToPeople, Equals, uint(0))
+	c.Check(report.AcresPlanted, Equals, uint(0))
+	c.Check(ks.YearOfRule(), Equals, uint(1))
+
+	// Even when the order's cost would overflow
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	report, err = ks.ApplyDecision(Decision{AcresToBuy: ^uint(0)/21 + 1})
+	c.Assert(err, IsNil)
+	c.Check(report.AcresBought, Equals, uint(2800/21))
+	c.Check(report.GrainUsedToBuyLand, Equals, uint(2800))
+}
+
+func (s *S) TestApplyDecisionStrict(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	ks.SetDecisionMode(Strict)
+	report, err := ks.ApplyDecision(Decision{AcresToBuy: 10000})
+	c.Check(errors.Is(err, ErrNotEnoughGrain), Equals, true)
+	c.Check(report, Equals, YearReport{})
+	c.Check(ks.YearOfRule(), Equals, uint(0))
+	c.Check(ks.Grain(), Equals, uint(2800))
+
+	// An order whose cost overflows is still too dear
+	report, err = ks.ApplyDecision(Decision{AcresToBuy: ^uint(0)/21 + 1})
+	c.Check(errors.Is(err, ErrNotEnoughGrain), Equals, true)
+	c.Check(report, Equals, YearReport{})
+	c.Check(ks.YearOfRule(), Equals, uint(0))
+
+	d := Decision{AcresToSell: 100, GrainForFood: 2000, AcresToPlant: 900}
+	report, err = ks.ApplyDecision(d)
+	c.Assert(err, IsNil)
+	c.Check(report.Decision, Equals, d)
+	c.Check(report.AcresSold, Equals, uint(100))
+	c.Check(report.GrainFedToPeople, Equals, uint(2000))
+	c.Check(report.AcresPlanted, Equals, uint(900))
+}
@@ -0,0 +1,161 @@
+package kingdomstate
+
+import (
+	"errors"
+	"math"
+)
+
+// DemographicRules describe the optional demographic model. Without it, the
+// people are all alike and the kingdom only grows by immigration. With it,
+// they are children, workers and elders: workers bear children, more of them
+// the better fed the kingdom, people of every age die naturally, and only
+// workers tend the fields. Immigrants come as workers; plague and starvation
+// strike every age alike.
+type DemographicRules struct {
+	Enabled bool
+
+	InitialChildrenPercent uint // share of the initial population who are children
+	InitialEldersPercent   uint // and who are elders; the rest are workers
+
+	ChildYears   float64 // years a child takes to become a worker, on average
+	WorkingYears float64 // years a worker takes to become an elder, on average
+
+	BirthPercent        float64 // children born each year to every hundred workers, if everyone was fed
+	SurplusBirthPercent float64 // more born if the kingdom has a year's food in store after the harvest, or fewer in proportion
+
+	ChildDeathPercent  float64 // share of the children who die naturally each year
+	WorkerDeathPercent float64
+	ElderDeathPercent  float64
+}
+
+// DefaultDemographicRules are the demographic model's rules once enabled.
+func DefaultDemographicRules() DemographicRules {
+	return DemographicRules{
+		InitialChildrenPercent: 30,
+		InitialEldersPercent:   10,
+		ChildYears:             12,
+		WorkingYears:           35,
+		BirthPercent:           3,
+		SurplusBirthPercent:    5,
+		ChildDeathPercent:      3,
+		WorkerDeathPercent:     1,
+		ElderDeathPercent:      12,
+	}
+}
+
+func (d DemographicRules) Validate() error {
+	switch {
+	case d.InitialChildrenPercent+d.InitialEldersPercent > 100:
+		return errors.New("Demographics.InitialChildrenPercent and InitialEldersPercent must add up to at most 100")
+	case d.ChildYears < 1 || d.WorkingYears < 1:
+		return errors.New("Demographics.ChildYears and WorkingYears must be at least 1")
+	case d.BirthPercent < 0 || d.SurplusBirthPercent < 0:
+		return errors.New("Demographics.BirthPercent and SurplusBirthPercent must not be negative")
+	case !isPercent(d.ChildDeathPercent) || !isPercent(d.WorkerDeathPercent) || !isPercent(d.ElderDeathPercent):
+		return errors.New("Demographics.ChildDeathPercent, WorkerDeathPercent and ElderDeathPercent must be between 0 and 100")
+	}
+	return nil
+}
+
+// share is the given share of n, rounded to the nearest person.
+func share(n uint, fraction float64) uint {
+	return min(uint(float64(n)*fraction+0.5), n)
+}
+
+// setupCohorts divides the initial population into children, workers and elders.
+func (ks *KingdomState) setupCohorts() {
+	d := ks.rules.Demographics
+	if !d.Enabled {
+		ks.children, ks.workers, ks.elders = 0, 0, 0
+		return
+	}
+	ks.children = ks.population * d.InitialChildrenPercent / 100
+	ks.elders = ks.population * d.InitialEldersPercent / 100
+	ks.workers = ks.population - ks.children - ks.elders
+}
+
+// removePeople takes n people out of the kingdom, from every age in proportion.
+func (ks *KingdomState) removePeople(n uint) {
+	n = min(n, ks.population)
+	if !ks.rules.Demographics.Enabled {
+		ks.population -= n
+		return
+	}
+	cohorts := []*uint{&ks.children, &ks.workers, &ks.elders}
+	removed := uint(0)
+	for _, c := range cohorts {
+		k := *c * n / ks.population
+		*c -= k
+		removed += k
+	}
+	// Whoever is left over by the rounding down comes from the workers first
+	for _, c := range []*uint{&ks.workers, &ks.children, &ks.elders} {
+		k := min(*c, n-removed)
+		*c -= k
+		removed += k
+	}
+	ks.population -= n
+}
+
+// addWorkers brings n workers into the kingdom.
+func (ks *KingdomState) addWorkers(n uint) {
+	ks.population += n
+	if ks.rules.Demographics.Enabled {
+		ks.workers += n
+	}
+}
+
+// ageOneYear plays out a year of births, natural deaths and growing up, given
+// the share of the people who were fed.
+func (ks *KingdomState) ageOneYear(r *YearReport, fed float64) {
+	d := ks.rules.Demographics
+	if !d.Enabled {
+		return
+	}
+
+	// Natural deaths
+	childDeaths := share(ks.children, d.ChildDeathPercent/100)
+	workerDeaths := share(ks.workers, d.WorkerDeathPercent/100)
+	elderDeaths := share(ks.elders, d.ElderDeathPercent/100)
+	ks.children -= childDeaths
+	ks.workers -= workerDeaths
+	ks.elders -= elderDeaths
+	r.NaturalDeaths = childDeaths + workerDeaths + elderDeaths
+
+	// Growing up and growing old
+	grownUp := share(ks.children, 1/d.ChildYears)
+	retired := share(ks.workers, 1/d.WorkingYears)
+	ks.children -= grownUp
+	ks.workers += grownUp - retired
+	ks.elders += retired
+
+	// Births, to the workers who are left
+	people := ks.children + ks.workers + ks.elders
+	var stores float64
+	if people > 0 {
+		stores = math.Min(1, float64(ks.grain)/float64(people*ks.rules.GrainPerPerson))
+	}
+	r.Births = share(ks.workers, (d.BirthPercent+d.SurplusBirthPercent*stores)*fed/100)
+	ks.children += r.Births
+
+	ks.population = ks.children + ks.workers + ks.elders
+}
+
+// Children is the number of children, if the rules have demographics.
+func (ks KingdomState) Children() uint {
+	return ks.children
+}
+
+// Workers is the number of people who can tend the fields: the workers, if
+// the rules have demographics, or else everyone.
+func (ks KingdomState) Workers() uint {
+	if !ks.rules.Demographics.Enabled {
+		return ks.population
+	}
+	return ks.workers
+}
+
+// Elders is the number of elders, if the rules have demographics.
+func (ks KingdomState) Elders() uint {
+	return ks.elders
+}
@@ -0,0 +1,109 @@
+package kingdomstate
+
+import (
+	. "github.com/go-check/check"
+)
+
+func demographicRules() Rules {
+	rules := DefaultRules()
+	rules.Demographics = DefaultDemographicRules()
+	rules.Demographics.Enabled = true
+	return rules
+}
+
+// demographicKingdom is a kingdom with demographics and the given cohorts.
+func demographicKingdom(children, workers, elders uint) KingdomState {
+	var ks KingdomState
+	ks.SetupInitialState(demographicRules(), FixedEvents{})
+	ks.children, ks.workers, ks.elders = children, workers, elders
+	ks.population = children + workers + elders
+	return ks
+}
+
+func (s *S) TestInitialCohorts(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(demographicRules(), FixedEvents{})
+	c.Check(ks.Children(), Equals, uint(30))
+	c.Check(ks.Workers(), Equals, uint(60))
+	c.Check(ks.Elders(), Equals, uint(10))
+	c.Check(ks.LastReport().Workers, Equals, uint(60))
+
+	// Without demographics everyone works
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	c.Check(ks.Workers(), Equals, ks.Population())
+	c.Check(ks.Children(), Equals, uint(0))
+	c.Check(ks.Elders(), Equals, uint(0))
+}
+
+func (s *S) TestOnlyWorkersPlant(c *C) {
+	ks := demographicKingdom(30, 60, 10)
+	ks.acreage = 3000
+	ks.grain = 10000
+	workable := 60 * ks.rules.AcresPerPerson
+
+	c.Check(ValidateDecision(ks, Decision{GrainForFood: 2000, AcresToPlant: workable}), IsNil)
+	c.Check(ValidateDecision(ks, Decision{GrainForFood: 2000, AcresToPlant: workable + 1}), ErrorMatches, ".*60 workers.*")
+
+	r, err := ks.TallyUpYear(0, 0, 2000, 3000)
+	c.Assert(err, IsNil)
+	c.Check(r.PlantingAcres, Equals, workable)
+}
+
+func (s *S) TestRemovePeople(c *C) {
+	ks := demographicKingdom(30, 60, 10)
+	ks.removePeople(50)
+	c.Check([]uint{ks.children, ks.workers, ks.elders}, DeepEquals, []uint{15, 30, 5})
+	c.Check(ks.population, Equals, uint(50))
+
+	// What the rounding leaves over comes from the workers
+	ks = demographicKingdom(30, 60, 10)
+	ks.removePeople(7)
+	c.Check([]uint{ks.children, ks.workers, ks.elders}, DeepEquals, []uint{28, 55, 10})
+	c.Check(ks.population, Equals, uint(93))
+
+	ks.removePeople(1000)
+	c.Check([]uint{ks.children, ks.workers, ks.elders}, DeepEquals, []uint{0, 0, 0})
+	c.Check(ks.population, Equals, uint(0))
+}
+
+func (s *S) TestAgeOneYear(c *C) {
+	ks := demographicKingdom(300, 600, 100)
+	ks.grain = 0
+	var r YearReport
+	ks.ageOneYear(&r, 1)
+
+	// 9 children, 6 workers and 12 elders die; 24 children grow up and 17
+	// workers grow old; the 601 workers bear 3% more children
+	c.Check(r.NaturalDeaths, Equals, uint(27))
+	c.Check(r.Births, Equals, uint(18))
+	c.Check([]uint{ks.children, ks.workers, ks.elders}, DeepEquals, []uint{285, 601, 105})
+	c.Check(ks.population, Equals, uint(991))
+}
+
+func (s *S) TestBirthsFollowFood(c *C) {
+	births := func(grain uint, fed float64) uint {
+		ks := demographicKingdom(300, 600, 100)
+		ks.grain = grain
+		var r YearReport
+		ks.ageOneYear(&r, fed)
+		return r.Births
+	}
+	none := births(0, 1)
+	c.Check(births(10000, 1) > none, Equals, true)
+	c.Check(births(20000, 1), Equals, births(40000, 1))
+	c.Check(births(0, 0.5) < none, Equals, true)
+	c.Check(births(20000, 0), Equals, uint(0))
+}
+
+func (s *S) TestDemographicGame(c *C) {
+	var ks KingdomState
+	ks.SetupSeededState(demographicRules(), 5)
+	RunGame(&ks, FeedThenPlantStrategy{})
+
+	h, _ := ks.History()
+	c.Assert(h.Years, Not(HasLen), 0)
+	for _, r := range h.Years {
+		c.Check(r.Children+r.Workers+r.Elders, Equals, r.EndOfYearPopulation, Commentf("year %d", r.Year))
+	}
+	c.Check(ks.Children()+ks.Workers()+ks.Elders(), Equals, ks.Population())
+}
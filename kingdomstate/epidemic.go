@@ -0,0 +1,145 @@
+package kingdomstate
+
+import (
+	"errors"
+	"math"
+)
+
+// EpidemicRules describe the optional epidemic model, which replaces the
+// classic plague. Disease breaks out more often, and spreads further, the
+// more crowded the land and the hungrier the people. Those who fall sick and
+// recover are immune to the next outbreak, until their immunity wanes.
+type EpidemicRules struct {
+	Enabled bool
+
+	OutbreakChance     float64 // chance of an outbreak in a year at the reference density, with everyone fed and nobody immune
+	ReferenceDensity   float64 // people per acre
+	MalnutritionFactor float64 // outbreaks are this much likelier, and spread this much further, for each share of the people unfed
+
+	R0                          float64 // people each sick person infects at the reference density, with everyone fed and nobody immune
+	FatalityPercent             float64 // share of the sick who die, among the fed
+	MalnourishedFatalityPercent float64 // share of the sick who die, among the unfed
+	ImmunityLossPercent         float64 // share of the immune who lose their immunity each year
+}
+
+// DefaultEpidemicRules are the epidemic's rules once enabled. A kingdom like
+// the one the ruler inherits suffers outbreaks about as often as the classic
+// plague, and a first outbreak kills nearly as many.
+func DefaultEpidemicRules() EpidemicRules {
+	return EpidemicRules{
+		OutbreakChance:              0.15,
+		ReferenceDensity:            0.1,
+		MalnutritionFactor:          2,
+		R0:                          2.5,
+		FatalityPercent:             50,
+		MalnourishedFatalityPercent: 80,
+		ImmunityLossPercent:         20,
+	}
+}
+
+func (e EpidemicRules) Validate() error {
+	switch {
+	case e.OutbreakChance < 0 || e.OutbreakChance > 1:
+		return errors.New("Epidemic.OutbreakChance must be between 0 and 1")
+	case e.ReferenceDensity <= 0:
+		return errors.New("Epidemic.ReferenceDensity must be positive")
+	case e.MalnutritionFactor < 0 || e.R0 < 0:
+		return errors.New("Epidemic.MalnutritionFactor and Epidemic.R0 must not be negative")
+	case !isPercent(e.FatalityPercent) || !isPercent(e.MalnourishedFatalityPercent) || !isPercent(e.ImmunityLossPercent):
+		return errors.New("Epidemic.FatalityPercent, MalnourishedFatalityPercent and ImmunityLossPercent must be between 0 and 100")
+	}
+	return nil
+}
+
+func isPercent(v float64) bool {
+	return v >= 0 && v <= 100
+}
+
+// EpidemicSource decides whether disease breaks out, for kingdoms whose rules
+// enable the epidemic.
+type EpidemicSource interface {
+	// Contagion is a draw from [0, 1]. Disease breaks out if it is above one
+	// less the year's chance of an outbreak, so 0 never brings disease and 1
+	// always does, if it can.
+	Contagion(year uint) float64
+}
+
+func (e *RandomEvents) Contagion(year uint) float64 {
+	return e.randgen.Float64()
+}
+
+// Contagion brings disease every fourth year, like the plague.
+func (e FixedEvents) Contagion(year uint) float64 {
+	if e.PlagueHappened(year) {
+		return 1
+	}
+	return 0
+}
+
+func (e ScriptedEvents) Contagion(year uint) float64 {
+	if y, ok := e.year(year); ok {
+		return y.Contagion
+	}
+	return epidemicSource(e.fallback()).Contagion(year)
+}
+
+func (e *RecordingEvents) Contagion(year uint) float64 {
+	v := epidemicSource(e.Source).Contagion(year)
+	e.year(year).Contagion = v
+	return v
+}
+
+// epidemicSource returns the source's contagion, or FixedEvents' if it has none.
+func epidemicSource(source EventSource) EpidemicSource {
+	if e, ok := source.(EpidemicSource); ok {
+		return e
+	}
+	return FixedEvents{}
+}
+
+// finalSize is the share of a people who fall sick over an outbreak in which
+// each sick person would infect r others if nobody were immune. It solves
+// z = 1 - exp(-r z), which has no root but zero when r is at most 1.
+func finalSize(r float64) float64 {
+	if r <= 1 {
+		return 0
+	}
+	z := 1.0
+	for i := 0; i < 100; i++ {
+		z = 1 - math.Exp(-r*z)
+	}
+	return z
+}
+
+// epidemic plays out the year's disease among the people, fed of whom were
+// fed, given the year's contagion draw. It reports whether disease broke out
+// and how many died of it, and updates who is immune.
+func (ks *KingdomState) epidemic(contagion float64, fed uint) (outbreak bool, victims uint) {
+	e := ks.rules.Epidemic
+	ks.immune -= uint(float64(ks.immune) * e.ImmunityLossPercent / 100)
+	ks.immune = min(ks.immune, ks.population)
+	if ks.population == 0 {
+		return false, 0
+	}
+
+	population := float64(ks.population)
+	unfed := 1 - float64(min(fed, ks.population))/population
+	density := population / math.Max(float64(ks.acreage), 1)
+	pressure := density / e.ReferenceDensity * (1 + e.MalnutritionFactor*unfed)
+	susceptible := 1 - float64(ks.immune)/population
+
+	chance := math.Min(1, e.OutbreakChance*pressure*susceptible)
+	if contagion <= 1-chance {
+		return false, 0
+	}
+	sick := uint(finalSize(e.R0*pressure*susceptible) * susceptible * population)
+	fatality := (e.FatalityPercent*(1-unfed) + e.MalnourishedFatalityPercent*unfed) / 100
+	victims = min(uint(float64(sick)*fatality+0.5), sick)
+	ks.immune += sick - victims
+	return sick > 0, victims
+}
+
+// Immune is the number of people immune to disease, if the rules have the epidemic.
+func (ks KingdomState) Immune() uint {
+	return ks.immune
+}
@@ -0,0 +1,109 @@
+package kingdomstate
+
+import (
+	"math"
+
+	. "github.com/go-check/check"
+)
+
+func epidemicRules() Rules {
+	rules := DefaultRules()
+	rules.Epidemic = DefaultEpidemicRules()
+	rules.Epidemic.Enabled = true
+	return rules
+}
+
+// epidemicKingdom is a kingdom with the epidemic, of the given people and land.
+func epidemicKingdom(population, acreage uint) KingdomState {
+	var ks KingdomState
+	ks.SetupInitialState(epidemicRules(), FixedEvents{})
+	ks.population = population
+	ks.acreage = acreage
+	return ks
+}
+
+func (s *S) TestFinalSize(c *C) {
+	c.Check(finalSize(0.5), Equals, 0.0)
+	c.Check(finalSize(1), Equals, 0.0)
+	z := finalSize(2)
+	c.Check(math.Abs(z-(1-math.Exp(-2*z))) < 1e-12, Equals, true)
+	c.Check(math.Abs(z-0.7968) < 1e-4, Equals, true, Commentf("z=%v", z))
+}
+
+func (s *S) TestOutbreakChance(c *C) {
+	// At the reference density, with everyone fed and nobody immune, the
+	// chance of an outbreak is OutbreakChance
+	ks := epidemicKingdom(100, 1000)
+	outbreak, _ := ks.epidemic(0.84, 100)
+	c.Check(outbreak, Equals, false)
+	outbreak, victims := ks.epidemic(0.86, 100)
+	c.Check(outbreak, Equals, true)
+	c.Check(victims > 0, Equals, true)
+
+	// Hunger and crowding make outbreaks likelier
+	ks = epidemicKingdom(100, 1000)
+	outbreak, _ = ks.epidemic(0.75, 50)
+	c.Check(outbreak, Equals, true)
+	ks = epidemicKingdom(100, 500)
+	outbreak, _ = ks.epidemic(0.75, 100)
+	c.Check(outbreak, Equals, true)
+
+	// but nothing ever breaks out with no contagion
+	ks = epidemicKingdom(100, 100)
+	outbreak, _ = ks.epidemic(0, 0)
+	c.Check(outbreak, Equals, false)
+}
+
+func (s *S) TestOutbreakSeverity(c *C) {
+	victims := func(population, acreage, fed uint) uint {
+		ks := epidemicKingdom(population, acreage)
+		_, v := ks.epidemic(1, fed)
+		return v
+	}
+	normal := victims(1000, 10000, 1000)
+	c.Check(normal > victims(1000, 40000, 1000), Equals, true)
+	c.Check(normal < victims(1000, 5000, 1000), Equals, true)
+	c.Check(normal < victims(1000, 10000, 500), Equals, true)
+
+	// Sparse enough, disease cannot spread
+	c.Check(victims(1000, 100000, 1000), Equals, uint(0))
+}
+
+func (s *S) TestImmunity(c *C) {
+	ks := epidemicKingdom(1000, 10000)
+	_, first := ks.epidemic(1, 1000)
+	ks.population -= first
+	c.Check(ks.Immune() > 0, Equals, true)
+	c.Check(ks.Immune() <= ks.population, Equals, true)
+
+	// Immunity spares many of the survivors from a second outbreak at the
+	// same density
+	ks.acreage = ks.population * 10
+	_, second := ks.epidemic(1, ks.population)
+	c.Check(second < first/2, Equals, true, Commentf("first %d, second %d", first, second))
+
+	// and wanes once the disease has gone
+	immune := ks.Immune()
+	ks.epidemic(0, ks.population)
+	c.Check(ks.Immune(), Equals, immune-immune/5)
+}
+
+func (s *S) TestEpidemicGame(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(epidemicRules(), FixedEvents{})
+	var reports []YearReport
+	for ks.StillInOffice() {
+		r, _ := ks.ApplyDecision(FeedThenPlantStrategy{}.Decide(ks))
+		reports = append(reports, r)
+	}
+	// Disease is only ever brought by the fixed events' fourth years
+	for _, r := range reports {
+		if r.Year%4 != 0 {
+			c.Check(r.PlagueHappened, Equals, false, Commentf("year %d", r.Year))
+			c.Check(r.PlagueVictims, Equals, uint(0), Commentf("year %d", r.Year))
+		}
+	}
+	c.Check(reports[3].PlagueHappened, Equals, true)
+	c.Check(reports[3].PlagueVictims > 0, Equals, true)
+	c.Check(reports[3].Immune > 0, Equals, true)
+}
@@ -0,0 +1,162 @@
+package kingdomstate
+
+import (
+	"math/rand"
+)
+
+// EventSource decides the events of each year of rule, which are beyond the
+// ruler's control. Years count from 1; the price of land for a year is asked
+// for during the year before it.
+type EventSource interface {
+	PricePerAcre(year uint) uint   // bushels per acre when trading land
+	YieldPerAcre(year uint) uint   // bushels harvested per acre planted
+	RatPercent(year uint) uint     // share of the grain in store eaten by rats
+	PlagueHappened(year uint) bool // whether plague strikes
+}
+
+// RandomEvents draws every event at random within the limits of the rules.
+type RandomEvents struct {
+	rules   Rules
+	randgen *rand.Rand
+}
+
+func NewRandomEvents(rules Rules, randgen *rand.Rand) *RandomEvents {
+	return &RandomEvents{rules: rules, randgen: randgen}
+}
+
+func (e *RandomEvents) PricePerAcre(year uint) uint {
+	return uint(e.randgen.Intn(int(e.rules.MaxPricePerAcre-e.rules.MinPricePerAcre+1))) + e.rules.MinPricePerAcre
+}
+
+func (e *RandomEvents) YieldPerAcre(year uint) uint {
+	return uint(e.randgen.Intn(int(e.rules.MaxYieldPerAcre-e.rules.MinYieldPerAcre+1))) + e.rules.MinYieldPerAcre
+}
+
+func (e *RandomEvents) RatPercent(year uint) uint {
+	if e.randgen.Float32() < float32(e.rules.RatChance) {
+		return uint(e.randgen.Intn(int(e.rules.MaxRatPercent-e.rules.MinRatPercent+1))) + e.rules.MinRatPercent
+	}
+	return 0
+}
+
+func (e *RandomEvents) PlagueHappened(year uint) bool {
+	return e.randgen.Float32() > float32(1-e.rules.PlagueChance)
+}
+
+// FixedEvents are the same every year, with plague every fourth year, for
+// deterministic tests.
+type FixedEvents struct{}
+
+func (FixedEvents) PricePerAcre(year uint) uint   { return 21 }
+func (FixedEvents) YieldPerAcre(year uint) uint   { return 3 }
+func (FixedEvents) RatPercent(year uint) uint     { return 10 }
+func (FixedEvents) PlagueHappened(year uint) bool { return year%4 == 0 }
+
+// ScriptedYear holds the events of one year.
+type ScriptedYear struct {
+	PricePerAcre uint
+	YieldPerAcre uint
+	RatPercent   uint
+	Plague       bool
+	Regime       Regime  // the weather, if the rules have weather
+	Forecast     Regime  // the forecast of the year's weather, made the year before
+	Contagion    float64 // decides whether disease breaks out, if the rules have the epidemic
+}
+
+// ScriptedEvents plays out a script of events, Years[0] being year 1. Years
+// past the end of the script come from Fallback, or FixedEvents if it is nil.
+type ScriptedEvents struct {
+	Years    []ScriptedYear
+	Fallback EventSource
+}
+
+// year returns the scripted events of the year, if there are any.
+func (e ScriptedEvents) year(year uint) (ScriptedYear, bool) {
+	if year == 0 || year > uint(len(e.Years)) {
+		return ScriptedYear{}, false
+	}
+	return e.Years[year-1], true
+}
+
+func (e ScriptedEvents) fallback() EventSource {
+	if e.Fallback == nil {
+		return FixedEvents{}
+	}
+	return e.Fallback
+}
+
+func (e ScriptedEvents) PricePerAcre(year uint) uint {
+	if y, ok := e.year(year); ok {
+		return y.PricePerAcre
+	}
+	return e.fallback().PricePerAcre(year)
+}
+
+func (e ScriptedEvents) YieldPerAcre(year uint) uint {
+	if y, ok := e.year(year); ok {
+		return y.YieldPerAcre
+	}
+	return e.fallback().YieldPerAcre(year)
+}
+
+func (e ScriptedEvents) RatPercent(year uint) uint {
+	if y, ok := e.year(year); ok {
+		return y.RatPercent
+	}
+	return e.fallback().RatPercent(year)
+}
+
+func (e ScriptedEvents) PlagueHappened(year uint) bool {
+	if y, ok := e.year(year); ok {
+		return y.Plague
+	}
+	return e.fallback().PlagueHappened(year)
+}
+
+// RecordingEvents passes on the events of another source, recording them so
+// that the game can be scripted to play out the same way again.
+type RecordingEvents struct {
+	Source EventSource
+	Years  []ScriptedYear
+}
+
+func NewRecordingEvents(source EventSource) *RecordingEvents {
+	return &RecordingEvents{Source: source}
+}
+
+// year returns the record of the year, making room for it if need be.
+func (e *RecordingEvents) year(year uint) *ScriptedYear {
+	for uint(len(e.Years)) < year {
+		e.Years = append(e.Years, ScriptedYear{})
+	}
+	return &e.Years[year-1]
+}
+
+func (e *RecordingEvents) PricePerAcre(year uint) uint {
+	v := e.Source.PricePerAcre(year)
+	e.year(year).PricePerAcre = v
+	return v
+}
+
+func (e *RecordingEvents) YieldPerAcre(year uint) uint {
+	v := e.Source.YieldPerAcre(year)
+	e.year(year).YieldPerAcre = v
+	return v
+}
+
+func (e *RecordingEvents) RatPercent(year uint) uint {
+	v := e.Source.RatPercent(year)
+	e.year(year).RatPercent = v
+	return v
+}
+
+func (e *RecordingEvents) PlagueHappened(year uint) bool {
+	v := e.Source.PlagueHappened(year)
+	e.year(year).Plague = v
+	return v
+}
+
+// Script returns the events recorded so far as a script.
+func (e *RecordingEvents) Script() ScriptedEvents {
+	return ScriptedEvents{Years: append([]ScriptedYear(nil), e.Years...)}
+}
@@ -0,0 +1,129 @@
+package kingdomstate
+
+import (
+	"math/rand"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestRandomPricePerAcre(c *C) {
+	events := NewRandomEvents(DefaultRules(), randgen)
+
+	totals := make(map[uint]int, 100)
+	for i := 0; i < 1000; i++ {
+		res := events.PricePerAcre(1)
+		totals[res] = totals[res] + 1
+	}
+	c.Check(totals[16] == 0, Equals, true)
+	for i := uint(17); i < 27; i++ {
+		c.Check(totals[i] != 0, Equals, true)
+	}
+	c.Check(totals[27] == 0, Equals, true)
+}
+
+func (s *S) TestRandomYieldPerAcre(c *C) {
+	events := NewRandomEvents(DefaultRules(), randgen)
+
+	totals := make(map[uint]int, 100)
+	for i := 0; i < 1000; i++ {
+		res := events.YieldPerAcre(1)
+		totals[res] = totals[res] + 1
+	}
+	c.Check(totals[0] == 0, Equals, true)
+	for i := uint(1); i < 6; i++ {
+		c.Check(totals[i] != 0, Equals, true)
+	}
+	c.Check(totals[6] == 0, Equals, true)
+}
+
+func (s *S) TestRandomRatPercent(c *C) {
+	events := NewRandomEvents(DefaultRules(), randgen)
+
+	totals := make(map[uint]int, 100)
+	for i := 0; i < 1000; i++ {
+		res := events.RatPercent(1)
+		totals[res] = totals[res] + 1
+	}
+	c.Check(totals[0] != 0, Equals, true)
+	c.Check(totals[9] == 0, Equals, true)
+	for i := uint(10); i < 31; i++ {
+		c.Check(totals[i] != 0, Equals, true)
+	}
+	c.Check(totals[31] == 0, Equals, true)
+}
+
+func (s *S) TestRandomPlagueHappened(c *C) {
+	events := NewRandomEvents(DefaultRules(), randgen)
+
+	seenTrue := false
+	seenFalse := false
+	for i := uint(0); i < 1000; i++ {
+		if events.PlagueHappened(i%10 + 1) {
+			seenTrue = true
+		} else {
+			seenFalse = true
+		}
+	}
+	c.Check(seenTrue, Equals, true)
+	c.Check(seenFalse, Equals, true)
+}
+
+func (s *S) TestFixedEvents(c *C) {
+	var events FixedEvents
+
+	c.Check(events.PricePerAcre(1), Equals, uint(21))
+	c.Check(events.YieldPerAcre(1), Equals, uint(3))
+	c.Check(events.RatPercent(1), Equals, uint(10))
+	c.Check(events.PlagueHappened(1), Equals, false)
+	c.Check(events.PlagueHappened(2), Equals, false)
+	c.Check(events.PlagueHappened(3), Equals, false)
+	c.Check(events.PlagueHappened(4), Equals, true)
+	c.Check(events.PlagueHappened(9), Equals, false)
+}
+
+func (s *S) TestScriptedEvents(c *C) {
+	var ks KingdomState
+
+	// Plague in year 2 and rats at 30%, with a bad harvest
+	events := ScriptedEvents{Years: []ScriptedYear{
+		{PricePerAcre: 20, YieldPerAcre: 3, RatPercent: 0},
+		{PricePerAcre: 25, YieldPerAcre: 1, RatPercent: 30, Plague: true},
+	}}
+	ks.SetupInitialState(DefaultRules(), events)
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(20))
+
+	r, _ := ks.ApplyDecision(Decision{GrainForFood: 2000, AcresToPlant: 1000})
+	c.Check(r.HarvestPerAcre, Equals, uint(3))
+	c.Check(r.GrainEatenByRats, Equals, uint(0))
+	c.Check(r.PlagueVictims, Equals, uint(0))
+	c.Check(r.NextYearPricePerAcre, Equals, uint(25))
+
+	population := ks.Population()
+	r, _ = ks.ApplyDecision(Decision{GrainForFood: population * GrainPerPerson, AcresToPlant: 1000})
+	c.Check(r.HarvestPerAcre, Equals, uint(1))
+	c.Check(r.PercentEatenByRats, Equals, uint(30))
+	c.Check(r.GrainEatenByRats, Equals, r.GrainAfterHarvest*30/100)
+	c.Check(r.PlagueVictims, Equals, population/2)
+
+	// The script has run out, so the fixed events follow
+	c.Check(r.NextYearPricePerAcre, Equals, uint(21))
+	c.Check(events.PlagueHappened(4), Equals, true)
+}
+
+func (s *S) TestRecordingEvents(c *C) {
+	var ks KingdomState
+	strategy := BuyLowSellHighStrategy{BuyBelow: 19, SellAbove: 24}
+
+	recording := NewRecordingEvents(NewRandomEvents(DefaultRules(), rand.New(NewSource(5))))
+	ks.SetupInitialState(DefaultRules(), recording)
+	RunGame(&ks, strategy)
+	c.Assert(recording.Years, HasLen, int(ks.YearOfRule())+1)
+	h := ks.LastReport()
+	c.Check(recording.Years[h.Year-1].YieldPerAcre, Equals, h.HarvestPerAcre)
+	c.Check(recording.Years[h.Year].PricePerAcre, Equals, h.NextYearPricePerAcre)
+
+	var scripted KingdomState
+	scripted.SetupInitialState(DefaultRules(), recording.Script())
+	RunGame(&scripted, strategy)
+	c.Check(scripted.LastReport(), Equals, ks.LastReport())
+}
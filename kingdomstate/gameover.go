@@ -0,0 +1,51 @@
+package kingdomstate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGameOver is returned when a ruler who has left office tries to rule.
+var ErrGameOver = errors.New("game over")
+
+// RemovalReason is why the ruler left office.
+type RemovalReason int
+
+const (
+	StillRuling    RemovalReason = iota // still in office
+	TermFinished                        // served the full term
+	EveryoneDied                        // nobody is left to rule
+	MassStarvation                      // impeached for starving too many in one year
+)
+
+var removalReasonNames = []string{
+	StillRuling:    "still ruling",
+	TermFinished:   "term finished",
+	EveryoneDied:   "everyone died",
+	MassStarvation: "mass starvation",
+}
+
+func (r RemovalReason) String() string {
+	if r < 0 || int(r) >= len(removalReasonNames) {
+		return fmt.Sprintf("RemovalReason(%d)", int(r))
+	}
+	return removalReasonNames[r]
+}
+
+func (ks KingdomState) gameOverError() error {
+	return fmt.Errorf("%w: %v in year %d", ErrGameOver, ks.gameOverReason, ks.yearOfRule)
+}
+
+func (r RemovalReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *RemovalReason) UnmarshalText(text []byte) error {
+	for i, name := range removalReasonNames {
+		if string(text) == name {
+			*r = RemovalReason(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown removal reason %q", text)
+}
@@ -0,0 +1,53 @@
+package kingdomstate
+
+import (
+	"errors"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestGameOverTermFinished(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	c.Check(ks.GameOverReason(), Equals, StillRuling)
+	for year := uint(1); year <= 10; year++ {
+		_, err := ks.TallyUpYear(0, 0, 2000, 1000)
+		c.Assert(err, IsNil)
+	}
+	c.Check(ks.StillInOffice(), Equals, false)
+	c.Check(ks.GameOverReason(), Equals, TermFinished)
+
+	_, err := ks.TallyUpYear(0, 0, 2000, 1000)
+	c.Check(errors.Is(err, ErrGameOver), Equals, true)
+	c.Check(ks.YearOfRule(), Equals, uint(10))
+
+	_, err = ks.ApplyDecision(Decision{})
+	c.Check(errors.Is(err, ErrGameOver), Equals, true)
+	ks.SetDecisionMode(Strict)
+	_, err = ks.ApplyDecision(Decision{AcresToBuy: 1000000})
+	c.Check(errors.Is(err, ErrGameOver), Equals, true)
+}
+
+func (s *S) TestGameOverMassStarvation(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	report, err := ks.TallyUpYear(0, 0, 1000, 1000)
+	c.Assert(err, IsNil)
+	c.Check(report.GameOverReason, Equals, MassStarvation)
+	c.Check(ks.StillInOffice(), Equals, false)
+	c.Check(ks.GameOverReason(), Equals, MassStarvation)
+	c.Check(ks.YearOfRule(), Equals, uint(1))
+}
+
+func (s *S) TestGameOverEveryoneDied(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	_, err := ks.TallyUpYear(0, 0, 0, 0)
+	c.Assert(err, IsNil)
+	c.Check(ks.Population(), Equals, uint(0))
+	c.Check(ks.GameOverReason(), Equals, EveryoneDied)
+	c.Check(ks.GameOverReason().String(), Equals, "everyone died")
+}
@@ -0,0 +1,87 @@
+package kingdomstate
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrReplayMismatch is returned when replaying a history does not reproduce it.
+var ErrReplayMismatch = errors.New("replay does not match history")
+
+// GameHistory is a complete record of a game, from which it can be replayed.
+type GameHistory struct {
+	Rules   Rules
+	Seed    int64        // seed of the random number generator driving the game
+	Initial YearReport   // the kingdom before the ruler took office
+	Years   []YearReport // each year of rule, including the decision made
+}
+
+// Decisions returns the decision made in each year of the game.
+func (h GameHistory) Decisions() []Decision {
+	decisions := make([]Decision, len(h.Years))
+	for i, r := range h.Years {
+		decisions[i] = r.Decision
+	}
+	return decisions
+}
+
+// SetupSeededState sets up the kingdom like SetupInitialState, with a random
+// number generator seeded with seed, and records its history as it is played.
+func (ks *KingdomState) SetupSeededState(rules Rules, seed int64) {
+	ks.SetupInitialState(rules, NewRandomEvents(rules, rand.New(NewSource(seed))))
+	ks.history = &GameHistory{Rules: rules, Seed: seed, Initial: ks.lastReport}
+}
+
+// History returns the game so far, if it was set up with SetupSeededState.
+func (ks KingdomState) History() (GameHistory, bool) {
+	if ks.history == nil {
+		return GameHistory{}, false
+	}
+	h := *ks.history
+	h.Years = append([]YearReport(nil), h.Years...)
+	return h, true
+}
+
+// Replay plays the recorded decisions again with the same rules and seed,
+// checking that every year turns out as recorded, and returns the kingdom as
+// it ends up.
+func Replay(h GameHistory) (KingdomState, error) {
+	var ks KingdomState
+
+	if h.Rules == (Rules{}) {
+		// Histories recorded before rules were configurable
+		h.Rules = DefaultRules()
+	}
+	if err := h.Rules.Validate(); err != nil {
+		return ks, err
+	}
+	ks.SetupSeededState(h.Rules, h.Seed)
+	h.Initial.fillIn(h.Rules)
+	if ks.lastReport != h.Initial {
+		return ks, fmt.Errorf("%w: initial state", ErrReplayMismatch)
+	}
+	for _, recorded := range h.Years {
+		recorded.fillIn(h.Rules)
+		r, err := ks.tally(recorded.Decision)
+		if err != nil {
+			return ks, fmt.Errorf("%w: year %d: %v", ErrReplayMismatch, recorded.Year, err)
+		}
+		if r != recorded {
+			return ks, fmt.Errorf("%w: year %d", ErrReplayMismatch, recorded.Year)
+		}
+	}
+	return ks, nil
+}
+
+// fillIn gives a report recorded before it had some of its fields what the
+// rules would have recorded in them: land sold and bought at its price before
+// the market, and everyone worked before demographics.
+func (r *YearReport) fillIn(rules Rules) {
+	if r.BidPerAcre == 0 && r.AskPerAcre == 0 {
+		r.BidPerAcre, r.AskPerAcre = r.PricePerAcre, r.PricePerAcre
+	}
+	if !rules.Demographics.Enabled && r.Workers == 0 {
+		r.Workers = r.EndOfYearPopulation
+	}
+}
@@ -0,0 +1,103 @@
+package kingdomstate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestHistory(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), NewRandomEvents(DefaultRules(), randgen))
+	_, ok := ks.History()
+	c.Check(ok, Equals, false)
+
+	ks.SetupSeededState(DefaultRules(), 42)
+	RunGame(&ks, FeedThenPlantStrategy{})
+	h, ok := ks.History()
+	c.Assert(ok, Equals, true)
+	c.Check(h.Seed, Equals, int64(42))
+	c.Check(h.Initial.Year, Equals, uint(0))
+	c.Assert(h.Years, HasLen, int(ks.YearOfRule()))
+	c.Check(h.Years[len(h.Years)-1], Equals, ks.LastReport())
+	for i, d := range h.Decisions() {
+		c.Check(h.Years[i].Year, Equals, uint(i+1))
+		c.Check(d, Equals, h.Years[i].Decision)
+	}
+
+	replayed, err := Replay(h)
+	c.Assert(err, IsNil)
+	c.Check(replayed.LastReport(), Equals, ks.LastReport())
+	c.Check(replayed.GameOverReason(), Equals, ks.GameOverReason())
+}
+
+func (s *S) TestHistoryJSON(c *C) {
+	var ks KingdomState
+
+	ks.SetupSeededState(DefaultRules(), 7)
+	RunGame(&ks, BuyLowSellHighStrategy{BuyBelow: 19, SellAbove: 24})
+	h, _ := ks.History()
+
+	data, err := json.Marshal(h)
+	c.Assert(err, IsNil)
+	var loaded GameHistory
+	c.Assert(json.Unmarshal(data, &loaded), IsNil)
+	c.Check(loaded.Years[len(loaded.Years)-1].GameOverReason, Equals, ks.GameOverReason())
+	_, err = Replay(loaded)
+	c.Check(err, IsNil)
+}
+
+func (s *S) TestReplayWithoutBidAsk(c *C) {
+	var ks KingdomState
+
+	ks.SetupSeededState(DefaultRules(), 7)
+	RunGame(&ks, BuyLowSellHighStrategy{BuyBelow: 19, SellAbove: 24})
+	h, _ := ks.History()
+
+	// Histories recorded before the market have no bid or ask
+	for i := range h.Years {
+		h.Years[i].BidPerAcre, h.Years[i].AskPerAcre = 0, 0
+	}
+	_, err := Replay(h)
+	c.Check(err, IsNil)
+}
+
+func (s *S) TestReplayOldHistory(c *C) {
+	// A history saved before reports had a bid, an ask or workers
+	data, err := os.ReadFile(filepath.Join("testdata", "history_before_market.json"))
+	c.Assert(err, IsNil)
+	var h GameHistory
+	c.Assert(json.Unmarshal(data, &h), IsNil)
+	c.Assert(h.Years, Not(HasLen), 0)
+
+	ks, err := Replay(h)
+	c.Assert(err, IsNil)
+	c.Check(ks.YearOfRule(), Equals, uint(len(h.Years)))
+}
+
+func (s *S) TestReplayMismatch(c *C) {
+	var ks KingdomState
+
+	ks.SetupSeededState(DefaultRules(), 3)
+	RunGame(&ks, FeedThenPlantStrategy{})
+	h, _ := ks.History()
+
+	h.Years[0].EndOfYearGrain++
+	_, err := Replay(h)
+	c.Check(errors.Is(err, ErrReplayMismatch), Equals, true)
+
+	h, _ = ks.History()
+	h.Years[0].Decision.GrainForFood = 0
+	_, err = Replay(h)
+	c.Check(errors.Is(err, ErrReplayMismatch), Equals, true)
+
+	h, _ = ks.History()
+	h.Years = append(h.Years, h.Years[len(h.Years)-1])
+	_, err = Replay(h)
+	c.Check(errors.Is(err, ErrReplayMismatch), Equals, true)
+	c.Check(errors.Is(err, ErrGameOver), Equals, false)
+}
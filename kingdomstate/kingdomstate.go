@@ -1,47 +1,9 @@
 package kingdomstate
 
 import (
-	"math/rand"
 	"fmt"
 )
 
-const (
-	GrainPerPerson = 20
-	AcresPerBushel = 2
-	AcresPerPerson = 20
-)
-
-func RandomPricePerAcre(randgen *rand.Rand) uint {
-	if randgen == nil {
-		return 21
-	}
-	return uint(randgen.Intn(10) + 17)
-}
-
-func RandomYieldPerAcre(randgen *rand.Rand) uint {
-	if randgen == nil {
-		return 3
-	}
-	return uint(randgen.Intn(5) + 1)
-}
-
-func RandomRatPercent(randgen *rand.Rand) uint {
-	if randgen == nil {
-		return 10
-	}
-	if randgen.Float32() < 0.40 {
-		return uint(randgen.Intn(21) + 10)
-	}
-	return 0
-}
-
-func RandomPlagueHappened(randgen *rand.Rand, year uint) bool {
-	if randgen == nil {
-		return (year%4 == 0)
-	}
-	return (randgen.Float32() > 0.85)
-}
-
 func min(i, j uint) uint {
 	if i < j { return i }
 	return j
@@ -53,12 +15,18 @@ func max(i, j uint) uint {
 }
 
 type KingdomState struct {
-	randgen *rand.Rand
+	rules Rules
+	events EventSource
+	decisionMode DecisionMode
 	
 	stillInOffice bool
+	gameOverReason RemovalReason
 	
 	yearOfRule uint
 	population uint
+	children uint
+	workers uint
+	elders uint
 	acreage uint
 	grain uint
 	
@@ -67,108 +35,252 @@ type KingdomState struct {
 	harvestPerAcre uint
 	percentEatenByRats uint
 	plagueHappened bool
+	regime Regime
+	forecast Regime
 	nextYearPricePerAcre uint
+	priceHistory []uint
 	
 	starvationVictims uint
+	totalStarvationVictims uint
+	sumPercentStarved float64
 	plagueVictims uint
+	immune uint
 	immigrants uint
 	grainHarvested uint
 	grainEatenByRats uint
+
+	lastReport YearReport
+	history *GameHistory
 }
 
-func (ks *KingdomState) SetupInitialState(randgen *rand.Rand) {
-	ks.randgen = randgen
+// SetupInitialState sets up the kingdom as the ruler takes office, with the
+// events of each year decided by events.
+func (ks *KingdomState) SetupInitialState(rules Rules, events EventSource) {
+	ks.rules = rules
+	ks.events = events
+	ks.history = nil
 	
 	ks.stillInOffice = true
+	ks.gameOverReason = StillRuling
 	
-	ks.population = 100
-	ks.acreage = 1000
-	ks.grain = 2800
+	ks.yearOfRule = 0
+	ks.population = rules.InitialPopulation
+	ks.setupCohorts()
+	ks.acreage = rules.InitialAcreage
+	ks.grain = rules.InitialGrain
 	
-	ks.harvestPerAcre = 3
-	ks.percentEatenByRats = 10
-	ks.nextYearPricePerAcre = RandomPricePerAcre(ks.randgen)
+	ks.harvestPerAcre = rules.InitialHarvestPerAcre
+	ks.percentEatenByRats = rules.InitialPercentEatenByRats
+	ks.plagueHappened = false
+	ks.regime = Normal
+	ks.nextYearPricePerAcre = events.PricePerAcre(1)
+	if weather := ks.weather(); weather != nil {
+		ks.forecast = weather.Forecast(1, ks.regime)
+	}
+	ks.priceHistory = nil
+	ks.recordPrice()
 
 	ks.starvationVictims = 0
+	ks.totalStarvationVictims = 0
+	ks.sumPercentStarved = 0
 	ks.plagueVictims = 0
-	ks.immigrants = 5
-	ks.grainHarvested = 3000
-	ks.grainEatenByRats = 400
+	ks.immune = 0
+	ks.immigrants = rules.InitialImmigrants
+	ks.grainHarvested = rules.InitialGrainHarvested
+	ks.grainEatenByRats = rules.InitialGrainEatenByRats
+
+	// Only the events and end of year values are known for the year before
+	// the ruler took office
+	ks.lastReport = ks.endOfYearReport(YearReport{})
+}
+
+// Clone returns a copy of the kingdom whose future events are decided by
+// events instead. The copy does not record its history.
+func (ks KingdomState) Clone(events EventSource) KingdomState {
+	clone := ks
+	clone.events = events
+	clone.history = nil
+	return clone
 }
 
-func (ks *KingdomState) TallyUpYear(acresToBuy, acresToSell, grainForFood, acresToPlant uint) {
-	if !ks.stillInOffice { panic(0) }
+// TallyUpYear plays out one year of rule, carrying out as much of each order as
+// possible, and reports what happened. It returns ErrGameOver if the ruler is
+// no longer in office.
+func (ks *KingdomState) TallyUpYear(acresToBuy, acresToSell, grainForFood, acresToPlant uint) (YearReport, error) {
+	return ks.tally(Decision{acresToBuy, acresToSell, grainForFood, acresToPlant})
+}
+
+// tally plays out one year of rule, clamping the orders to what can be done.
+func (ks *KingdomState) tally(d Decision) (YearReport, error) {
+	if !ks.stillInOffice {
+		return YearReport{}, ks.gameOverError()
+	}
 	
-	var startOfYearPopulation uint = ks.population
+	r := YearReport{
+		Decision: d,
+		StartOfYearPopulation: ks.population,
+		StartOfYearAcreage: ks.acreage,
+		StartOfYearGrain: ks.grain,
+	}
 	
 	// Cummulative values
 	ks.yearOfRule++
 	ks.pricePerAcre = ks.nextYearPricePerAcre
 
 	// Random events
-	ks.harvestPerAcre = RandomYieldPerAcre(ks.randgen)
-	ks.percentEatenByRats = RandomRatPercent(ks.randgen)
-	ks.plagueHappened = RandomPlagueHappened(ks.randgen, ks.yearOfRule)  
-	ks.nextYearPricePerAcre = RandomPricePerAcre(ks.randgen)
+	weather := ks.weather()
+	if weather != nil {
+		ks.regime = weather.Regime(ks.yearOfRule, ks.regime, ks.forecast)
+		ks.harvestPerAcre = weather.YieldIn(ks.yearOfRule, ks.regime)
+	} else {
+		ks.harvestPerAcre = ks.events.YieldPerAcre(ks.yearOfRule)
+	}
+	ks.percentEatenByRats = ks.events.RatPercent(ks.yearOfRule)
+	var contagion float64
+	if ks.rules.Epidemic.Enabled {
+		contagion = epidemicSource(ks.events).Contagion(ks.yearOfRule)
+	} else {
+		ks.plagueHappened = ks.events.PlagueHappened(ks.yearOfRule)
+	}
+	drawnPrice := ks.events.PricePerAcre(ks.yearOfRule + 1)
+	if weather != nil {
+		ks.forecast = weather.Forecast(ks.yearOfRule + 1, ks.regime)
+	}
 	
 	// Buy land
-	grainUsedToBuyLand := min(acresToBuy * ks.pricePerAcre, ks.grain)
-	ks.grain -= grainUsedToBuyLand
-	ks.acreage += grainUsedToBuyLand / ks.pricePerAcre
+	r.BidPerAcre, r.AskPerAcre = ks.bidAsk(ks.pricePerAcre)
+	if d.AcresToBuy > ks.grain / r.AskPerAcre {
+		// All the grain goes, without working out a cost that may overflow
+		r.GrainUsedToBuyLand = ks.grain
+	} else {
+		r.GrainUsedToBuyLand = d.AcresToBuy * r.AskPerAcre
+	}
+	ks.grain -= r.GrainUsedToBuyLand
+	r.AcresBought = r.GrainUsedToBuyLand / r.AskPerAcre
+	ks.acreage += r.AcresBought
 
 	// Sell land
-	grainFromSaleOfLand := min(acresToSell, ks.acreage) * ks.pricePerAcre
-	ks.grain += grainFromSaleOfLand
-	ks.acreage -= grainFromSaleOfLand / ks.pricePerAcre
+	r.GrainFromSaleOfLand = min(d.AcresToSell, ks.acreage) * r.BidPerAcre
+	ks.grain += r.GrainFromSaleOfLand
+	r.AcresSold = r.GrainFromSaleOfLand / r.BidPerAcre
+	ks.acreage -= r.AcresSold
+	r.GrainAfterBartering = ks.grain
+
+	// The market sets next year's price
+	ks.nextYearPricePerAcre = ks.marketPrice(drawnPrice, r.AcresBought, r.AcresSold)
+	ks.recordPrice()
 	
 	// Feed the people
-	peopleFed := min( min(ks.grain, grainForFood) / GrainPerPerson, ks.population)
-	ks.grain -= peopleFed * GrainPerPerson
+	r.PeopleFed = min( min(ks.grain, d.GrainForFood) / ks.rules.GrainPerPerson, ks.population)
+	r.GrainFedToPeople = r.PeopleFed * ks.rules.GrainPerPerson
+	ks.grain -= r.GrainFedToPeople
+	r.GrainAfterFeeding = ks.grain
 	
 	// Plant the fields
-	acresForPlanting :=	min( min(acresToPlant, ks.population * AcresPerPerson), ks.acreage )
-	grainPlanted := min(ks.grain, acresForPlanting / AcresPerBushel)
-	acresPlanted := grainPlanted * AcresPerBushel
-	ks.grain -= grainPlanted
-	grainAfterPlanting := ks.grain
+	r.PlantingAcres = min( min(d.AcresToPlant, ks.Workers() * ks.rules.AcresPerPerson), ks.acreage )
+	r.GrainPlanted = min(ks.grain, r.PlantingAcres / ks.rules.AcresPerBushel)
+	r.AcresPlanted = r.GrainPlanted * ks.rules.AcresPerBushel
+	ks.grain -= r.GrainPlanted
+	r.GrainAfterPlanting = ks.grain
 	
 	// Harvest grain and deal with the rats
-	ks.grainHarvested = acresPlanted * ks.harvestPerAcre
+	ks.grainHarvested = r.AcresPlanted * ks.harvestPerAcre
 	ks.grain += ks.grainHarvested
+	r.GrainAfterHarvest = ks.grain
 	ks.grainEatenByRats = ks.percentEatenByRats * ks.grain / 100
 	ks.grain -= ks.grainEatenByRats
 	
 	// Adjust population counts
-	if ks.plagueHappened {
-		ks.plagueVictims = ks.population / 2
-		ks.population -= ks.plagueVictims
-	} else {
+	switch {
+	case ks.rules.Epidemic.Enabled:
+		ks.plagueHappened, ks.plagueVictims = ks.epidemic(contagion, r.PeopleFed)
+		ks.removePeople(ks.plagueVictims)
+	case ks.plagueHappened:
+		ks.plagueVictims = ks.population * ks.rules.PlagueDeathPercent / 100
+		ks.removePeople(ks.plagueVictims)
+	default:
 		ks.plagueVictims = 0
 	}
+	r.PostPlaguePopulation = ks.population
 	
-	if ks.population > peopleFed {
+	if ks.population > r.PeopleFed {
 		// Starvation occurs if not everyone was fed
-		ks.starvationVictims = ks.population - peopleFed
-		ks.population -= ks.starvationVictims
+		ks.starvationVictims = ks.population - r.PeopleFed
+		ks.totalStarvationVictims += ks.starvationVictims
+		ks.sumPercentStarved += 100 * float64(ks.starvationVictims) / float64(r.StartOfYearPopulation)
+		ks.removePeople(ks.starvationVictims)
 	} else {
 		ks.starvationVictims = 0
 	}
 	
 	if ks.population > 0 && ks.starvationVictims == 0 {
 		// Allow immigrants if nobody starved and there are still people around
-		ks.immigrants = (20 * ks.acreage + grainAfterPlanting) / (100 * ks.population) + 1
-		ks.population += ks.immigrants
+		ks.immigrants = (20 * ks.acreage + r.GrainAfterPlanting) / (100 * ks.population) + 1
+		ks.addWorkers(ks.immigrants)
 	} else {
 		ks.immigrants = 0
 	}
+	
+	// People are born, die of old age and grow up, if the rules have demographics
+	fed := 1.0
+	if r.PostPlaguePopulation > 0 {
+		fed = float64(min(r.PeopleFed, r.PostPlaguePopulation)) / float64(r.PostPlaguePopulation)
+	}
+	ks.ageOneYear(&r, fed)
+	ks.immune = min(ks.immune, ks.population)
 
 	// Determine if the game is over
 	ks.stillInOffice = (
-		ks.yearOfRule < 10 &&
+		ks.yearOfRule < ks.rules.TermYears &&
 		ks.population > 0 &&
-		ks.starvationVictims < 45 * startOfYearPopulation / 100)
+		ks.starvationVictims < ks.rules.StarvationLimitPercent * r.StartOfYearPopulation / 100)
+	switch {
+	case ks.stillInOffice:
+	case ks.population == 0:
+		ks.gameOverReason = EveryoneDied
+	case ks.starvationVictims >= ks.rules.StarvationLimitPercent * r.StartOfYearPopulation / 100:
+		ks.gameOverReason = MassStarvation
+	default:
+		ks.gameOverReason = TermFinished
+	}
+
+	ks.lastReport = ks.endOfYearReport(r)
+	if ks.history != nil {
+		ks.history.Years = append(ks.history.Years, ks.lastReport)
+	}
+	return ks.lastReport, nil
 }
 
+// endOfYearReport completes a report with the kingdom's state at the end of the year.
+func (ks *KingdomState) endOfYearReport(r YearReport) YearReport {
+	r.Year = ks.yearOfRule
+	r.PricePerAcre = ks.pricePerAcre
+	r.HarvestPerAcre = ks.harvestPerAcre
+	r.PercentEatenByRats = ks.percentEatenByRats
+	r.PlagueHappened = ks.plagueHappened
+	r.Regime = ks.regime
+	r.Forecast = ks.forecast
+	r.NextYearPricePerAcre = ks.nextYearPricePerAcre
+	r.GrainHarvested = ks.grainHarvested
+	r.GrainEatenByRats = ks.grainEatenByRats
+	r.PlagueVictims = ks.plagueVictims
+	r.Immune = ks.immune
+	r.StarvationVictims = ks.starvationVictims
+	r.Immigrants = ks.immigrants
+	r.EndOfYearPopulation = ks.population
+	r.Children = ks.children
+	r.Workers = ks.Workers()
+	r.Elders = ks.elders
+	r.EndOfYearAcreage = ks.acreage
+	r.EndOfYearGrain = ks.grain
+	r.StillInOffice = ks.stillInOffice
+	r.GameOverReason = ks.gameOverReason
+	return r
+}
+
+func (ks KingdomState) Rules() Rules {
+	return ks.rules
+}
 func (ks KingdomState) YearOfRule() uint {
 	return ks.yearOfRule
 }
@@ -181,22 +293,49 @@ func (ks KingdomState) Grain() uint {
 func (ks KingdomState) Acreage() uint {
 	return ks.acreage
 }
+func (ks KingdomState) NextYearPricePerAcre() uint {
+	return ks.nextYearPricePerAcre
+}
+func (ks KingdomState) StarvationVictims() uint {
+	return ks.starvationVictims
+}
+func (ks KingdomState) TotalStarvationVictims() uint {
+	return ks.totalStarvationVictims
+}
 
 func (ks KingdomState) StillInOffice() bool {
 	return ks.stillInOffice
 }
+func (ks KingdomState) GameOverReason() RemovalReason {
+	return ks.gameOverReason
+}
+
+// LastReport reports on the most recent year of rule.
+func (ks KingdomState) LastReport() YearReport {
+	return ks.lastReport
+}
 
 func (ks KingdomState) PrintSummary() {
 	fmt.Printf("___________________________________________________________________")
 	fmt.Printf("\nO Great Hammurabi!\n")
-	fmt.Printf("You are in year %d of your ten year rule.\n", ks.yearOfRule + 1)
+	fmt.Printf("You are in year %d of your %d year rule.\n", ks.yearOfRule + 1, ks.rules.TermYears)
 	if (ks.plagueVictims > 0) {
 		fmt.Printf("A horrible plague killed %d people.\n", ks.plagueVictims)
 	}
 	fmt.Printf("In the previous year %d people starved to death.\n", ks.starvationVictims)
 	fmt.Printf("In the previous year %d people entered the kingdom.\n", ks.immigrants)
 	fmt.Printf("The population is now %d.\n", ks.population)
+	if ks.rules.Demographics.Enabled {
+		fmt.Printf("In the previous year %d children were born and %d people died of old age and illness.\n", ks.lastReport.Births, ks.lastReport.NaturalDeaths)
+		fmt.Printf("There are %d children, %d workers and %d elders.\n", ks.children, ks.workers, ks.elders)
+	}
 	fmt.Printf("We harvested %d bushels at %d bushels per acre.\n", ks.grainHarvested, ks.harvestPerAcre)
+	if ks.rules.Weather.Enabled {
+		fmt.Printf("The weather was %v.\n", ks.regime)
+	}
+	if forecast, ok := ks.Forecast(); ok {
+		fmt.Printf("The astrologers foretell %v weather this year.\n", forecast)
+	}
 	if (ks.grainEatenByRats > 0) {
 		fmt.Printf("*** Rats destroyed %d bushels, leaving %d bushels in storage.\n", ks.grainEatenByRats, ks.grain)
 	} else {
@@ -204,5 +343,8 @@ func (ks KingdomState) PrintSummary() {
 	}
 	fmt.Printf("The city owns %d acres of land.\n", ks.acreage)
 	fmt.Printf("Land is currently worth %d bushels per acre.\n", ks.nextYearPricePerAcre)
+	if ks.rules.Market.Enabled {
+		fmt.Printf("Buyers pay %d bushels per acre and sellers get %d.\n", ks.NextYearAskPerAcre(), ks.NextYearBidPerAcre())
+	}
 }
 
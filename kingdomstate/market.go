@@ -0,0 +1,87 @@
+package kingdomstate
+
+import (
+	"errors"
+	"math"
+)
+
+// MarketRules describe the optional land market. Without it, the price of
+// land is whatever the events draw each year. With it, the price drifts part
+// of the way towards the drawn price each year, rises as the ruler buys land
+// and falls as the ruler sells, and buyers pay more than sellers get.
+type MarketRules struct {
+	Enabled            bool
+	ReversionPercent   float64 // share of the gap to the drawn price closed each year
+	ImpactPer1000Acres float64 // bushels per acre the price rises for every thousand acres bought, or falls for every thousand sold
+	Spread             uint    // bushels per acre between the ask, which buyers pay, and the bid, which sellers get
+}
+
+// DefaultMarketRules are the market's rules once enabled. A market enabled
+// with nothing else thus has a spread and a price that reverts, rather than
+// one that never returns from wherever trading pushes it.
+func DefaultMarketRules() MarketRules {
+	return MarketRules{
+		ReversionPercent:   50,
+		ImpactPer1000Acres: 5,
+		Spread:             2,
+	}
+}
+
+func (m MarketRules) Validate() error {
+	switch {
+	case m.ReversionPercent < 0 || m.ReversionPercent > 100:
+		return errors.New("Market.ReversionPercent must be between 0 and 100")
+	case m.ImpactPer1000Acres < 0:
+		return errors.New("Market.ImpactPer1000Acres must not be negative")
+	}
+	return nil
+}
+
+// bidAsk returns what sellers get and buyers pay for an acre of land at the
+// price. Sellers always get at least a bushel.
+func (ks KingdomState) bidAsk(price uint) (bid, ask uint) {
+	if !ks.rules.Market.Enabled {
+		return price, price
+	}
+	spread := ks.rules.Market.Spread
+	bid = price - min(spread/2, price-1)
+	return bid, bid + spread
+}
+
+// NextYearBidPerAcre is what the kingdom gets for each acre it sells this year.
+func (ks KingdomState) NextYearBidPerAcre() uint {
+	bid, _ := ks.bidAsk(ks.nextYearPricePerAcre)
+	return bid
+}
+
+// NextYearAskPerAcre is what the kingdom pays for each acre it buys this year.
+func (ks KingdomState) NextYearAskPerAcre() uint {
+	_, ask := ks.bidAsk(ks.nextYearPricePerAcre)
+	return ask
+}
+
+// marketPrice is the price of land next year, given the price the events
+// drew for it and the land the ruler traded this year.
+func (ks KingdomState) marketPrice(drawn, acresBought, acresSold uint) uint {
+	m := ks.rules.Market
+	if !m.Enabled {
+		return drawn
+	}
+	price := float64(ks.pricePerAcre)
+	price += m.ReversionPercent / 100 * (float64(drawn) - price)
+	price += m.ImpactPer1000Acres * (float64(acresBought) - float64(acresSold)) / 1000
+	return uint(math.Max(1, math.Floor(price+0.5)))
+}
+
+// recordPrice adds next year's price to the price history. Copies of the
+// kingdom share the history's array, so it is copied rather than grown in place.
+func (ks *KingdomState) recordPrice() {
+	n := len(ks.priceHistory)
+	ks.priceHistory = append(ks.priceHistory[:n:n], ks.nextYearPricePerAcre)
+}
+
+// PriceHistory returns the price of land in every year of rule so far,
+// followed by this year's.
+func (ks KingdomState) PriceHistory() []uint {
+	return append([]uint(nil), ks.priceHistory...)
+}
@@ -0,0 +1,109 @@
+package kingdomstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-check/check"
+)
+
+func marketRules(reversion, impact float64, spread uint) Rules {
+	rules := DefaultRules()
+	rules.Market = MarketRules{Enabled: true, ReversionPercent: reversion, ImpactPer1000Acres: impact, Spread: spread}
+	return rules
+}
+
+var marketEvents = ScriptedEvents{Years: []ScriptedYear{
+	{PricePerAcre: 20, YieldPerAcre: 3},
+	{PricePerAcre: 30, YieldPerAcre: 3},
+	{PricePerAcre: 30, YieldPerAcre: 3},
+}}
+
+func (s *S) TestNoMarket(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(DefaultRules(), marketEvents)
+	c.Check(ks.NextYearBidPerAcre(), Equals, uint(20))
+	c.Check(ks.NextYearAskPerAcre(), Equals, uint(20))
+
+	// Selling does not move the price
+	r, _ := ks.ApplyDecision(Decision{AcresToSell: 500, GrainForFood: 2000})
+	c.Check(r.GrainFromSaleOfLand, Equals, uint(500*20))
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(30))
+	c.Check(ks.PriceHistory(), DeepEquals, []uint{20, 30})
+}
+
+func (s *S) TestMarketPrice(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(marketRules(50, 5, 0), marketEvents)
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(20))
+
+	// Half way to the drawn 30, less a bushel for every 200 acres sold
+	r, err := ks.ApplyDecision(Decision{AcresToSell: 400, GrainForFood: 2000})
+	c.Assert(err, IsNil)
+	c.Check(r.AcresSold, Equals, uint(400))
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(23))
+	c.Check(r.NextYearPricePerAcre, Equals, uint(23))
+
+	// Half way to 30 again, plus a bushel for every 200 acres bought
+	_, err = ks.ApplyDecision(Decision{AcresToBuy: 100, GrainForFood: 2000})
+	c.Assert(err, IsNil)
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(27))
+	c.Check(ks.PriceHistory(), DeepEquals, []uint{20, 23, 27})
+}
+
+func (s *S) TestMarketPriceStaysPositive(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(marketRules(0, 1000, 0), marketEvents)
+	ks.ApplyDecision(Decision{AcresToSell: 1000, GrainForFood: 2000})
+	c.Check(ks.NextYearPricePerAcre(), Equals, uint(1))
+}
+
+func (s *S) TestSpread(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(marketRules(100, 0, 4), marketEvents)
+	c.Check(ks.NextYearBidPerAcre(), Equals, uint(18))
+	c.Check(ks.NextYearAskPerAcre(), Equals, uint(22))
+
+	ks.SetDecisionMode(Strict)
+	_, err := ks.ApplyDecision(Decision{AcresToBuy: 128})
+	c.Check(errors.Is(err, ErrNotEnoughGrain), Equals, true)
+	r, err := ks.ApplyDecision(Decision{AcresToBuy: 10, GrainForFood: 2000})
+	c.Assert(err, IsNil)
+	c.Check(r.AskPerAcre, Equals, uint(22))
+	c.Check(r.GrainUsedToBuyLand, Equals, uint(220))
+
+	r, err = ks.ApplyDecision(Decision{AcresToSell: 10})
+	c.Assert(err, IsNil)
+	c.Check(r.BidPerAcre, Equals, uint(28))
+	c.Check(r.GrainFromSaleOfLand, Equals, uint(280))
+}
+
+func (s *S) TestPriceHistoryOfCopies(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(marketRules(50, 5, 0), marketEvents)
+	ks.ApplyDecision(Decision{GrainForFood: 2000})
+
+	buyer, seller := ks, ks
+	buyer.ApplyDecision(Decision{AcresToBuy: 100, GrainForFood: 2000})
+	seller.ApplyDecision(Decision{AcresToSell: 100, GrainForFood: 2000})
+	c.Check(buyer.PriceHistory(), DeepEquals, []uint{20, 25, 28})
+	c.Check(seller.PriceHistory(), DeepEquals, []uint{20, 25, 27})
+	c.Check(ks.PriceHistory(), DeepEquals, []uint{20, 25})
+}
+
+func (s *S) TestLoadMarketRules(c *C) {
+	filename := filepath.Join(c.MkDir(), "rules.json")
+	err := os.WriteFile(filename, []byte(`{"Market": {"Enabled": true, "Spread": 6}}`), 0644)
+	c.Assert(err, IsNil)
+
+	rules, err := LoadRules(filename)
+	c.Assert(err, IsNil)
+	expected := DefaultMarketRules()
+	expected.Enabled = true
+	expected.Spread = 6
+	c.Check(rules.Market, Equals, expected)
+
+	rules.Market.ReversionPercent = 101
+	c.Check(rules.Validate(), NotNil)
+}
@@ -0,0 +1,93 @@
+package kingdomstate
+
+import (
+	"fmt"
+)
+
+// Tier is the classic end-of-reign verdict on the ruler.
+type Tier int
+
+const (
+	NationalFink Tier = iota // impeached, or dreadful mismanagement
+	Nero                     // heavy-handed, like Nero and Ivan IV
+	Average                  // could have been somewhat better
+	Charlemagne              // a fantastic performance
+)
+
+var tierNames = []string{
+	NationalFink: "national fink",
+	Nero:         "Nero",
+	Average:      "average",
+	Charlemagne:  "Charlemagne",
+}
+
+func (t Tier) String() string {
+	if t < 0 || int(t) >= len(tierNames) {
+		return fmt.Sprintf("Tier(%d)", int(t))
+	}
+	return tierNames[t]
+}
+
+// Rating is the evaluation of a reign, as in the original game.
+type Rating struct {
+	Years          uint
+	PercentStarved float64 // average percent of the population starved each year
+	TotalStarved   uint
+	AcresPerPerson float64 // at the end, or zero if nobody is left
+	Tier           Tier
+}
+
+// Evaluate rates the reign so far. A ruler removed from office before the end
+// of the term is always a national fink.
+func (ks KingdomState) Evaluate() Rating {
+	var percentStarved float64
+	if ks.yearOfRule > 0 {
+		percentStarved = ks.sumPercentStarved / float64(ks.yearOfRule)
+	}
+	removed := ks.gameOverReason == EveryoneDied || ks.gameOverReason == MassStarvation
+	r := Rate(ks.yearOfRule, percentStarved, ks.population, ks.acreage, removed)
+	r.TotalStarved = ks.totalStarvationVictims
+	return r
+}
+
+// Rate rates a reign of the given years, in which on average percentStarved
+// of the people starved each year, that left the given population and
+// acreage. It leaves TotalStarved zero.
+func Rate(years uint, percentStarved float64, population, acreage uint, removed bool) Rating {
+	r := Rating{
+		Years:          years,
+		PercentStarved: percentStarved,
+	}
+	if population > 0 {
+		r.AcresPerPerson = float64(acreage) / float64(population)
+	}
+
+	switch {
+	case removed:
+		r.Tier = NationalFink
+	case r.PercentStarved > 33 || r.AcresPerPerson < 7:
+		r.Tier = NationalFink
+	case r.PercentStarved > 10 || r.AcresPerPerson < 9:
+		r.Tier = Nero
+	case r.PercentStarved > 3 || r.AcresPerPerson < 10:
+		r.Tier = Average
+	default:
+		r.Tier = Charlemagne
+	}
+	return r
+}
+
+// Score puts ratings on a single scale for comparing strategies: 10 points for
+// each tier, 1 for each year in office, and less than 1 more for having plenty
+// of land (up to 20 acres per person) and starving few.
+func (r Rating) Score() float64 {
+	acres := r.AcresPerPerson
+	if acres > 20 {
+		acres = 20
+	}
+	fed := (100 - r.PercentStarved) / 100
+	if fed < 0 {
+		fed = 0
+	}
+	return 10*float64(r.Tier) + float64(r.Years) + 0.999*(acres/20+fed)/2
+}
@@ -0,0 +1,77 @@
+package kingdomstate
+
+import (
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestEvaluateDeterministicSequence(c *C) {
+	var ks KingdomState
+	var starved uint
+	var percent float64
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	for year := uint(1); year <= 10; year++ {
+		r, _ := ks.TallyUpYear(acresToBuy(year, ks.acreage), acresToSell(year, ks.acreage), grainForFood(year, ks.population), acresToPlant(year))
+		starved += r.StarvationVictims
+		percent += 100 * float64(r.StarvationVictims) / float64(r.StartOfYearPopulation)
+	}
+
+	r := ks.Evaluate()
+	c.Check(r.Years, Equals, uint(10))
+	c.Check(r.TotalStarved, Equals, starved)
+	c.Check(r.PercentStarved, Equals, percent/10)
+	c.Check(r.AcresPerPerson, Equals, 1059.0/21)
+	c.Check(r.Tier, Equals, Average)
+}
+
+func (s *S) TestEvaluateTiers(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	for ks.StillInOffice() {
+		ks.TallyUpYear(0, 0, ks.population*GrainPerPerson, min(ks.acreage, ks.population*AcresPerPerson))
+	}
+	c.Check(ks.GameOverReason(), Equals, TermFinished)
+	r := ks.Evaluate()
+	c.Check(r.TotalStarved, Equals, uint(0))
+	c.Check(r.Tier, Equals, Charlemagne)
+
+	ks.sumPercentStarved = 50
+	c.Check(ks.Evaluate().Tier, Equals, Average)
+	ks.sumPercentStarved = 200
+	c.Check(ks.Evaluate().Tier, Equals, Nero)
+	ks.sumPercentStarved = 0
+	ks.population = ks.acreage / 8
+	c.Check(ks.Evaluate().Tier, Equals, Nero)
+	ks.population = ks.acreage / 5
+	c.Check(ks.Evaluate().Tier, Equals, NationalFink)
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	ks.TallyUpYear(0, 0, 1000, 0)
+	c.Check(ks.GameOverReason(), Equals, MassStarvation)
+	c.Check(ks.Evaluate().Tier, Equals, NationalFink)
+}
+
+func (s *S) TestRatingScore(c *C) {
+	impeached := Rating{Years: 3, Tier: NationalFink, AcresPerPerson: 20}
+	finished := Rating{Years: 10, Tier: NationalFink, PercentStarved: 40}
+	nero := Rating{Years: 10, Tier: Nero, PercentStarved: 11, AcresPerPerson: 10}
+	average := Rating{Years: 10, Tier: Average, AcresPerPerson: 9}
+	better := Rating{Years: 10, Tier: Average, AcresPerPerson: 9.5}
+	best := Rating{Years: 10, Tier: Charlemagne, AcresPerPerson: 10}
+
+	c.Check(impeached.Score() < finished.Score(), Equals, true)
+	c.Check(finished.Score() < nero.Score(), Equals, true)
+	c.Check(nero.Score() < average.Score(), Equals, true)
+	c.Check(average.Score() < better.Score(), Equals, true)
+	c.Check(better.Score() < best.Score(), Equals, true)
+	c.Check(Rating{Years: 10, Tier: Charlemagne, AcresPerPerson: 100}.Score() < 41, Equals, true)
+}
+
+func (s *S) TestRate(c *C) {
+	c.Check(Rate(10, 0, 100, 1000, false).Tier, Equals, Charlemagne)
+	c.Check(Rate(10, 5, 100, 1000, false).Tier, Equals, Average)
+	c.Check(Rate(10, 0, 100, 1000, true).Tier, Equals, NationalFink)
+	c.Check(Rate(4, 0, 0, 1000, false).AcresPerPerson, Equals, 0.0)
+	c.Check(Rate(10, 0, 100, 1000, false).TotalStarved, Equals, uint(0))
+}
@@ -0,0 +1,63 @@
+package kingdomstate
+
+// YearReport is a ledger of one year of rule, from the state of the kingdom
+// at the start of the year, through each step of TallyUpYear, to its state at
+// the end of the year.
+type YearReport struct {
+	Year     uint
+	Decision Decision // the orders as given
+
+	StartOfYearPopulation uint
+	StartOfYearAcreage    uint
+	StartOfYearGrain      uint
+
+	// Random events
+	PricePerAcre         uint
+	HarvestPerAcre       uint
+	PercentEatenByRats   uint
+	PlagueHappened       bool
+	Regime               Regime // the weather, which is always normal if the rules have none
+	Forecast             Regime // of next year's weather, if the rules have weather
+	NextYearPricePerAcre uint
+
+	// Trading land
+	BidPerAcre          uint // what sellers got for each acre
+	AskPerAcre          uint // what buyers paid for each acre
+	AcresBought         uint
+	GrainUsedToBuyLand  uint
+	AcresSold           uint
+	GrainFromSaleOfLand uint
+	GrainAfterBartering uint
+
+	// Feeding the people
+	PeopleFed         uint
+	GrainFedToPeople  uint
+	GrainAfterFeeding uint
+
+	// Planting and harvesting
+	PlantingAcres      uint // acres the orders, people and land allowed to be planted
+	GrainPlanted       uint
+	AcresPlanted       uint
+	GrainAfterPlanting uint
+	GrainHarvested     uint
+	GrainAfterHarvest  uint
+	GrainEatenByRats   uint
+
+	// Population changes
+	PlagueVictims        uint
+	Immune               uint // people immune to disease at the end of the year, if the rules have the epidemic
+	PostPlaguePopulation uint
+	StarvationVictims    uint
+	Immigrants           uint
+	Births               uint // if the rules have demographics
+	NaturalDeaths        uint // if the rules have demographics
+
+	EndOfYearPopulation uint
+	Children            uint // if the rules have demographics
+	Workers             uint // everyone, if the rules have no demographics
+	Elders              uint // if the rules have demographics
+	EndOfYearAcreage    uint
+	EndOfYearGrain      uint
+	StillInOffice       bool
+	GameOverReason      RemovalReason
+}
@@ -0,0 +1,171 @@
+package kingdomstate
+
+import (
+	. "github.com/go-check/check"
+)
+
+// These port the assertions of the original Scala test suite to YearReport.
+
+var expectedEOYPopulation = []uint{100, 98, 94, 88, 49, 44, 38, 32, 33, 27, 21}
+var expectedEOYAcreage = []uint{1000, 1010, 990, 990, 1029, 978, 978, 1046, 963, 963, 1059}
+var expectedEOYGrain = []uint{2800, 2840, 3470, 3767, 3527, 5547, 6289, 5509, 7499, 7749, 5997}
+var expectedEOYStillInOffice = []bool{true, true, true, true, true, true, true, true, true, true, false}
+
+// deterministicReports plays the deterministic sequence and returns the
+// report for each year, starting with the year before the ruler took office.
+func deterministicReports() []YearReport {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	reports := []YearReport{ks.LastReport()}
+	for year := uint(1); year <= 10; year++ {
+		r, _ := ks.TallyUpYear(acresToBuy(year, ks.acreage), acresToSell(year, ks.acreage), grainForFood(year, ks.population), acresToPlant(year))
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// secondYear plays the second year of the deterministic sequence with other orders.
+func secondYear(d Decision) YearReport {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	ks.TallyUpYear(acresToBuy(1, ks.acreage), acresToSell(1, ks.acreage), grainForFood(1, ks.population), acresToPlant(1))
+	r, _ := ks.ApplyDecision(d)
+	return r
+}
+
+func (s *S) TestReportYearOfRule(c *C) {
+	for i, r := range deterministicReports() {
+		c.Check(r.Year, Equals, uint(i))
+	}
+}
+
+func (s *S) TestReportStartOfYear(c *C) {
+	reports := deterministicReports()
+	for i := 1; i < len(reports); i++ {
+		c.Check(reports[i].StartOfYearPopulation, Equals, reports[i-1].EndOfYearPopulation)
+		c.Check(reports[i].StartOfYearAcreage, Equals, reports[i-1].EndOfYearAcreage)
+		c.Check(reports[i].StartOfYearGrain, Equals, reports[i-1].EndOfYearGrain)
+	}
+}
+
+func (s *S) TestReportRandomEvents(c *C) {
+	reports := deterministicReports()
+	plagues := 0
+	for i, r := range reports {
+		c.Check(int(r.HarvestPerAcre), IntegerBetween, 1, 5)
+		c.Check(r.PercentEatenByRats == 0 || r.PercentEatenByRats >= 10, Equals, true)
+		c.Check(int(r.PercentEatenByRats), IntegerBetween, 0, 30)
+		c.Check(int(r.NextYearPricePerAcre), IntegerBetween, 17, 26)
+		if i > 0 {
+			c.Check(r.PricePerAcre, Equals, reports[i-1].NextYearPricePerAcre)
+		}
+		if r.PlagueHappened {
+			plagues++
+		}
+	}
+	c.Check(plagues > 0, Equals, true)
+	c.Check(plagues < len(reports), Equals, true)
+}
+
+func (s *S) TestReportTrading(c *C) {
+	allSold := secondYear(Decision{AcresToSell: 10000, GrainForFood: 2000, AcresToPlant: 200})
+	c.Check(allSold.AcresSold, Equals, allSold.StartOfYearAcreage)
+
+	allAcres := secondYear(Decision{AcresToSell: 10000, GrainForFood: 10000, AcresToPlant: 10000})
+	c.Check(allAcres.GrainFromSaleOfLand, Equals, allAcres.StartOfYearAcreage*allAcres.PricePerAcre)
+
+	allGrain := secondYear(Decision{AcresToBuy: 10000, GrainForFood: 10000, AcresToPlant: 10000})
+	c.Check(allGrain.GrainUsedToBuyLand, Equals, allGrain.StartOfYearGrain)
+	c.Check(allGrain.GrainAfterBartering, Equals, uint(0))
+
+	for _, r := range deterministicReports()[1:] {
+		c.Check(r.GrainUsedToBuyLand <= r.StartOfYearGrain, Equals, true)
+		c.Check(r.AcresBought, Equals, r.GrainUsedToBuyLand/r.PricePerAcre)
+		c.Check(r.AcresSold <= r.StartOfYearAcreage, Equals, true)
+		c.Check(r.GrainFromSaleOfLand, Equals, r.AcresSold*r.PricePerAcre)
+		c.Check(r.GrainAfterBartering, Equals, r.StartOfYearGrain-r.GrainUsedToBuyLand+r.GrainFromSaleOfLand)
+	}
+}
+
+func (s *S) TestReportFeeding(c *C) {
+	allFed := secondYear(Decision{AcresToSell: 10000, GrainForFood: 100000, AcresToPlant: 10000})
+	c.Check(allFed.PeopleFed, Equals, allFed.StartOfYearPopulation)
+	c.Check(allFed.GrainFedToPeople, Equals, allFed.StartOfYearPopulation*GrainPerPerson)
+
+	noTrade := secondYear(Decision{GrainForFood: 10000, AcresToPlant: 10000})
+	c.Check(noTrade.GrainAfterFeeding, Equals, noTrade.StartOfYearGrain-noTrade.StartOfYearPopulation*GrainPerPerson)
+
+	for _, r := range deterministicReports()[1:] {
+		c.Check(r.PeopleFed <= r.StartOfYearPopulation, Equals, true)
+		c.Check(r.GrainFedToPeople, Equals, r.PeopleFed*GrainPerPerson)
+		c.Check(r.GrainFedToPeople <= r.GrainAfterBartering, Equals, true)
+		c.Check(r.GrainAfterFeeding, Equals, r.GrainAfterBartering-r.GrainFedToPeople)
+	}
+}
+
+func (s *S) TestReportPlanting(c *C) {
+	allPlanted := secondYear(Decision{AcresToPlant: 100000})
+	c.Check(allPlanted.PlantingAcres, Equals, allPlanted.EndOfYearAcreage)
+	c.Check(allPlanted.GrainPlanted, Equals, allPlanted.EndOfYearAcreage/AcresPerBushel)
+	c.Check(allPlanted.GrainAfterPlanting, Equals, allPlanted.StartOfYearGrain-allPlanted.EndOfYearAcreage/AcresPerBushel)
+	c.Check(allPlanted.GrainHarvested, Equals, allPlanted.AcresPlanted*allPlanted.HarvestPerAcre)
+
+	for _, r := range deterministicReports()[1:] {
+		c.Check(r.PlantingAcres <= r.StartOfYearAcreage+r.AcresBought-r.AcresSold, Equals, true)
+		c.Check(r.GrainPlanted <= r.GrainAfterFeeding, Equals, true)
+		c.Check(r.AcresPlanted <= r.PlantingAcres, Equals, true)
+		c.Check(r.GrainAfterPlanting, Equals, r.GrainAfterFeeding-r.GrainPlanted)
+		c.Check(r.GrainHarvested, Equals, r.AcresPlanted*r.HarvestPerAcre)
+		c.Check(r.GrainAfterHarvest, Equals, r.GrainAfterPlanting+r.GrainHarvested)
+		if r.PercentEatenByRats > 0 {
+			c.Check(r.GrainEatenByRats <= r.GrainAfterHarvest/2, Equals, true)
+		} else {
+			c.Check(r.GrainEatenByRats, Equals, uint(0))
+		}
+		c.Check(r.EndOfYearGrain, Equals, r.GrainAfterHarvest-r.GrainEatenByRats)
+	}
+}
+
+func (s *S) TestReportPopulation(c *C) {
+	for _, r := range deterministicReports()[1:] {
+		if r.PlagueHappened {
+			c.Check(r.PlagueVictims <= r.StartOfYearPopulation, Equals, true)
+			c.Check(r.PostPlaguePopulation <= r.StartOfYearPopulation, Equals, true)
+		} else {
+			c.Check(r.PlagueVictims, Equals, uint(0))
+			c.Check(r.PostPlaguePopulation, Equals, r.StartOfYearPopulation)
+		}
+		c.Check(r.StarvationVictims <= r.StartOfYearPopulation, Equals, true)
+		if r.StarvationVictims == 0 {
+			c.Check(r.Immigrants <= r.StartOfYearPopulation, Equals, true)
+		} else {
+			c.Check(r.Immigrants, Equals, uint(0))
+		}
+		c.Check(r.EndOfYearPopulation, Equals, r.PostPlaguePopulation-r.StarvationVictims+r.Immigrants)
+	}
+}
+
+func (s *S) TestReportEndOfYear(c *C) {
+	for i, r := range deterministicReports() {
+		c.Check(r.EndOfYearPopulation, Equals, expectedEOYPopulation[i])
+		c.Check(r.EndOfYearAcreage, Equals, expectedEOYAcreage[i])
+		c.Check(r.EndOfYearGrain, Equals, expectedEOYGrain[i])
+		c.Check(r.StillInOffice, Equals, expectedEOYStillInOffice[i])
+	}
+}
+
+func (s *S) TestReportRandomInitialState(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), NewRandomEvents(DefaultRules(), randgen))
+	r := ks.LastReport()
+	c.Check(r.Year, Equals, uint(0))
+	c.Check(r.EndOfYearPopulation, Equals, uint(100))
+	c.Check(r.EndOfYearAcreage, Equals, uint(1000))
+	c.Check(r.EndOfYearGrain, Equals, uint(2800))
+	c.Check(r.StillInOffice, Equals, true)
+	c.Check(r.PlagueHappened, Equals, false)
+	c.Check(int(r.NextYearPricePerAcre), IntegerBetween, 17, 26)
+}
@@ -0,0 +1,155 @@
+package kingdomstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The classic values of the most used rules.
+const (
+	GrainPerPerson = 20
+	AcresPerBushel = 2
+	AcresPerPerson = 20
+)
+
+// MaxPerAcre bounds the prices and yields the rules may set, so that their
+// ranges can be drawn from and a kingdom's worth does not overflow.
+const MaxPerAcre = 1 << 20
+
+// Rules are the parameters of the game.
+type Rules struct {
+	GrainPerPerson         uint // bushels each person eats in a year
+	AcresPerBushel         uint // acres planted with each bushel of seed
+	AcresPerPerson         uint // acres each person can tend
+	TermYears              uint // length of the ruler's term
+	StarvationLimitPercent uint // the ruler is impeached if this share of the people starve in one year
+
+	InitialPopulation uint
+	InitialAcreage    uint
+	InitialGrain      uint
+
+	// What happened in the year before the ruler took office
+	InitialHarvestPerAcre     uint
+	InitialPercentEatenByRats uint
+	InitialGrainHarvested     uint
+	InitialGrainEatenByRats   uint
+	InitialImmigrants         uint
+
+	MinPricePerAcre uint
+	MaxPricePerAcre uint
+	MinYieldPerAcre uint
+	MaxYieldPerAcre uint
+
+	RatChance     float64 // chance of rats in any year
+	MinRatPercent uint    // share of the grain eaten by rats when they come
+	MaxRatPercent uint
+
+	PlagueChance       float64 // chance of plague in any year
+	PlagueDeathPercent uint    // share of the people killed by plague
+
+	Market   MarketRules   // off in the classic game
+	Weather  WeatherRules  // off in the classic game
+	Epidemic EpidemicRules // replaces the plague; off in the classic game
+
+	Demographics DemographicRules // off in the classic game
+}
+
+// DefaultRules are the rules of the classic game. Its optional models are
+// off but hold their own defaults, so that rules given over these need only
+// enable a model and change what they mean to.
+func DefaultRules() Rules {
+	return Rules{
+		GrainPerPerson:         GrainPerPerson,
+		AcresPerBushel:         AcresPerBushel,
+		AcresPerPerson:         AcresPerPerson,
+		TermYears:              10,
+		StarvationLimitPercent: 45,
+
+		InitialPopulation: 100,
+		InitialAcreage:    1000,
+		InitialGrain:      2800,
+
+		InitialHarvestPerAcre:     3,
+		InitialPercentEatenByRats: 10,
+		InitialGrainHarvested:     3000,
+		InitialGrainEatenByRats:   400,
+		InitialImmigrants:         5,
+
+		MinPricePerAcre: 17,
+		MaxPricePerAcre: 26,
+		MinYieldPerAcre: 1,
+		MaxYieldPerAcre: 5,
+
+		RatChance:     0.40,
+		MinRatPercent: 10,
+		MaxRatPercent: 30,
+
+		PlagueChance:       0.15,
+		PlagueDeathPercent: 50,
+
+		Market:   DefaultMarketRules(),
+		Weather:  DefaultWeatherRules(),
+		Epidemic: DefaultEpidemicRules(),
+
+		Demographics: DefaultDemographicRules(),
+	}
+}
+
+// Validate checks that the rules make a playable game.
+func (r Rules) Validate() error {
+	switch {
+	case r.GrainPerPerson == 0 || r.AcresPerBushel == 0 || r.AcresPerPerson == 0:
+		return errors.New("GrainPerPerson, AcresPerBushel and AcresPerPerson must be positive")
+	case r.TermYears == 0:
+		return errors.New("TermYears must be positive")
+	case r.StarvationLimitPercent == 0:
+		return errors.New("StarvationLimitPercent must be positive")
+	case r.InitialPopulation == 0:
+		return errors.New("InitialPopulation must be positive")
+	case r.MinPricePerAcre == 0 || r.MinPricePerAcre > r.MaxPricePerAcre || r.MaxPricePerAcre > MaxPerAcre:
+		return fmt.Errorf("need 0 < MinPricePerAcre <= MaxPricePerAcre <= %d", MaxPerAcre)
+	case r.MinYieldPerAcre > r.MaxYieldPerAcre || r.MaxYieldPerAcre > MaxPerAcre:
+		return fmt.Errorf("need MinYieldPerAcre <= MaxYieldPerAcre <= %d", MaxPerAcre)
+	case r.MinRatPercent > r.MaxRatPercent || r.MaxRatPercent > 100:
+		return errors.New("need MinRatPercent <= MaxRatPercent <= 100")
+	case r.RatChance < 0 || r.RatChance > 1 || r.PlagueChance < 0 || r.PlagueChance > 1:
+		return errors.New("RatChance and PlagueChance must be between 0 and 1")
+	case r.PlagueDeathPercent > 100:
+		return errors.New("PlagueDeathPercent must be at most 100")
+	}
+	if err := r.Market.Validate(); err != nil {
+		return err
+	}
+	if r.Weather.Enabled {
+		if err := r.Weather.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Epidemic.Enabled {
+		if err := r.Epidemic.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Demographics.Enabled {
+		return r.Demographics.Validate()
+	}
+	return nil
+}
+
+// LoadRules reads rules from a JSON file. Any rule the file leaves out keeps its default.
+func LoadRules(filename string) (Rules, error) {
+	rules := DefaultRules()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return rules, err
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return rules, fmt.Errorf("%s: %v", filename, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,148 @@
+package kingdomstate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestDefaultRules(c *C) {
+	c.Check(DefaultRules().Validate(), IsNil)
+}
+
+func (s *S) TestValidateRules(c *C) {
+	broken := []func(r *Rules){
+		func(r *Rules) { r.GrainPerPerson = 0 },
+		func(r *Rules) { r.TermYears = 0 },
+		func(r *Rules) { r.StarvationLimitPercent = 0 },
+		func(r *Rules) { r.InitialPopulation = 0 },
+		func(r *Rules) { r.MinPricePerAcre = 0 },
+		func(r *Rules) { r.MaxPricePerAcre = ^uint(0) },
+		func(r *Rules) { r.MaxPricePerAcre = MaxPerAcre + 1 },
+		func(r *Rules) { r.MinYieldPerAcre = r.MaxYieldPerAcre + 1 },
+		func(r *Rules) { r.MinYieldPerAcre, r.MaxYieldPerAcre = 0, ^uint(0) },
+		func(r *Rules) {
+			r.Weather = DefaultWeatherRules()
+			r.Weather.Enabled = true
+			r.Weather.Drought.MaxYieldPerAcre = ^uint(0)
+		},
+		func(r *Rules) {
+			r.Weather.Enabled = true
+			r.Weather.Drought.MinYieldPerAcre = 3
+		},
+		func(r *Rules) {
+			r.Weather.Enabled = true
+			r.Weather.Abundant = RegimeRules{MaxYieldPerAcre: 5}
+		},
+		func(r *Rules) {
+			r.Epidemic.Enabled = true
+			r.Epidemic.ReferenceDensity = 0
+		},
+		func(r *Rules) {
+			r.Epidemic.Enabled = true
+			r.Epidemic.FatalityPercent = 120
+		},
+		func(r *Rules) {
+			r.Demographics.Enabled = true
+			r.Demographics.InitialEldersPercent = 80
+		},
+		func(r *Rules) {
+			r.Demographics.Enabled = true
+			r.Demographics.ChildYears = 0
+		},
+		func(r *Rules) {
+			r.Demographics.Enabled = true
+			r.Demographics.ElderDeathPercent = 120
+		},
+		func(r *Rules) { r.MaxRatPercent = 101 },
+		func(r *Rules) { r.PlagueChance = 1.5 },
+		func(r *Rules) { r.PlagueDeathPercent = 101 },
+	}
+	for i, breakIt := range broken {
+		r := DefaultRules()
+		breakIt(&r)
+		c.Check(r.Validate(), NotNil, Commentf("case %d", i))
+	}
+}
+
+func (s *S) TestRulesFromJSON(c *C) {
+	tuned := DefaultRules()
+	tuned.Market.Enabled = true
+	tuned.Market.ReversionPercent = 10
+
+	tests := []struct {
+		over   Rules
+		json   string
+		change func(r *Rules)
+	}{
+		{DefaultRules(), `{"Market": {"Enabled": true, "Spread": 6}}`, func(r *Rules) { r.Market.Enabled, r.Market.Spread = true, 6 }},
+		{DefaultRules(), `{"Weather": {"Enabled": true, "ForecastAccuracy": 0.9}}`, func(r *Rules) { r.Weather.Enabled, r.Weather.ForecastAccuracy = true, 0.9 }},
+		{DefaultRules(), `{"Epidemic": {"Enabled": true, "R0": 3}}`, func(r *Rules) { r.Epidemic.Enabled, r.Epidemic.R0 = true, 3 }},
+		{DefaultRules(), `{"Demographics": {"Enabled": true, "BirthPercent": 4}}`, func(r *Rules) { r.Demographics.Enabled, r.Demographics.BirthPercent = true, 4 }},
+
+		// Nested rules given over others change only what they give
+		{tuned, `{"Market": {"Spread": 6}}`, func(r *Rules) { r.Market.Spread = 6 }},
+		{DefaultRules(), `{"Weather": {"Drought": {"MaxYieldPerAcre": 3}}}`, func(r *Rules) { r.Weather.Drought.MaxYieldPerAcre = 3 }},
+	}
+	for i, t := range tests {
+		rules := t.over
+		c.Assert(json.Unmarshal([]byte(t.json), &rules), IsNil, Commentf("case %d", i))
+		expected := t.over
+		t.change(&expected)
+		c.Check(rules, Equals, expected, Commentf("case %d", i))
+		c.Check(rules.Validate(), IsNil, Commentf("case %d", i))
+	}
+}
+
+func (s *S) TestLoadRules(c *C) {
+	filename := filepath.Join(c.MkDir(), "rules.json")
+	err := os.WriteFile(filename, []byte(`{"TermYears": 20, "PlagueChance": 0}`), 0644)
+	c.Assert(err, IsNil)
+
+	rules, err := LoadRules(filename)
+	c.Assert(err, IsNil)
+	expected := DefaultRules()
+	expected.TermYears = 20
+	expected.PlagueChance = 0
+	c.Check(rules, Equals, expected)
+
+	err = os.WriteFile(filename, []byte(`{"TermYears": 0}`), 0644)
+	c.Assert(err, IsNil)
+	_, err = LoadRules(filename)
+	c.Check(err, NotNil)
+
+	_, err = LoadRules(filepath.Join(c.MkDir(), "missing.json"))
+	c.Check(err, NotNil)
+}
+
+func (s *S) TestLongerTerm(c *C) {
+	var ks KingdomState
+	rules := DefaultRules()
+	rules.TermYears = 20
+
+	ks.SetupInitialState(rules, FixedEvents{})
+	RunGame(&ks, FeedThenPlantStrategy{})
+	c.Check(ks.GameOverReason(), Equals, TermFinished)
+	c.Check(ks.YearOfRule(), Equals, uint(20))
+}
+
+func (s *S) TestRulesInHistory(c *C) {
+	var ks KingdomState
+	rules := DefaultRules()
+	rules.PlagueChance = 0
+
+	ks.SetupSeededState(rules, 3)
+	RunGame(&ks, FeedThenPlantStrategy{})
+	h, _ := ks.History()
+	c.Check(h.Rules, Equals, rules)
+	for _, r := range h.Years {
+		c.Check(r.PlagueHappened, Equals, false)
+	}
+
+	// Replaying under other rules does not reproduce the game
+	h.Rules.PlagueChance = 1
+	_, err := Replay(h)
+	c.Check(err, NotNil)
+}
@@ -0,0 +1,110 @@
+package kingdomstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrScenarioMismatch is returned when a scenario does not play out as expected.
+var ErrScenarioMismatch = errors.New("scenario does not play out as expected")
+
+// Scenario is a scripted game: the rules, which include the kingdom's initial
+// state, the events of each year, the decision made in each year, and what
+// the kingdom is expected to look like at the end of each year.
+type Scenario struct {
+	Name   string
+	Rules  Rules
+	Strict bool           // play the decisions in Strict mode
+	Events []ScriptedYear // years past the end of these have FixedEvents
+	Years  []ScenarioYear
+}
+
+// ScenarioYear is the decision made in one year of a scenario and what is
+// expected at the end of it.
+type ScenarioYear struct {
+	Decision Decision
+	Expect   Expectation
+}
+
+// Expectation holds the expected end of year values. Those left nil are not checked.
+type Expectation struct {
+	Population        *uint
+	Acreage           *uint
+	Grain             *uint
+	StarvationVictims *uint
+	PlagueVictims     *uint
+	Immigrants        *uint
+	StillInOffice     *bool
+	GameOverReason    *RemovalReason
+}
+
+// LoadScenario reads a scenario from a JSON file. Any rule the file leaves
+// out keeps its default.
+func LoadScenario(filename string) (Scenario, error) {
+	sc := Scenario{Rules: DefaultRules()}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return sc, err
+	}
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return sc, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := sc.Rules.Validate(); err != nil {
+		return sc, fmt.Errorf("%s: %v", filename, err)
+	}
+	if sc.Name == "" {
+		sc.Name = filename
+	}
+	return sc, nil
+}
+
+// Run plays the scenario, returning an error for the first year that does
+// not turn out as expected.
+func (sc Scenario) Run() error {
+	var ks KingdomState
+
+	ks.SetupInitialState(sc.Rules, ScriptedEvents{Years: sc.Events})
+	if sc.Strict {
+		ks.SetDecisionMode(Strict)
+	}
+	for i, y := range sc.Years {
+		r, err := ks.ApplyDecision(y.Decision)
+		if err != nil {
+			return fmt.Errorf("%s: year %d: %w", sc.Name, i+1, err)
+		}
+		if err := y.Expect.check(r); err != nil {
+			return fmt.Errorf("%w: %s: year %d: %v", ErrScenarioMismatch, sc.Name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// check compares the expected values with the end of year report.
+func (e Expectation) check(r YearReport) error {
+	uints := []struct {
+		name     string
+		expected *uint
+		actual   uint
+	}{
+		{"population", e.Population, r.EndOfYearPopulation},
+		{"acreage", e.Acreage, r.EndOfYearAcreage},
+		{"grain", e.Grain, r.EndOfYearGrain},
+		{"starvation victims", e.StarvationVictims, r.StarvationVictims},
+		{"plague victims", e.PlagueVictims, r.PlagueVictims},
+		{"immigrants", e.Immigrants, r.Immigrants},
+	}
+	for _, u := range uints {
+		if u.expected != nil && *u.expected != u.actual {
+			return fmt.Errorf("%s is %d, expected %d", u.name, u.actual, *u.expected)
+		}
+	}
+	if e.StillInOffice != nil && *e.StillInOffice != r.StillInOffice {
+		return fmt.Errorf("still in office is %v, expected %v", r.StillInOffice, *e.StillInOffice)
+	}
+	if e.GameOverReason != nil && *e.GameOverReason != r.GameOverReason {
+		return fmt.Errorf("game over reason is %v, expected %v", r.GameOverReason, *e.GameOverReason)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package kingdomstate
+
+import (
+	"errors"
+	"path/filepath"
+
+	. "github.com/go-check/check"
+)
+
+// TestScenarios plays every scenario under testdata/scenarios.
+func (s *S) TestScenarios(c *C) {
+	files, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.json"))
+	c.Assert(err, IsNil)
+	c.Assert(files, Not(HasLen), 0)
+	for _, f := range files {
+		sc, err := LoadScenario(f)
+		if !c.Check(err, IsNil) {
+			continue
+		}
+		c.Check(sc.Run(), IsNil, Commentf("%s", f))
+	}
+}
+
+func (s *S) TestScenarioMismatch(c *C) {
+	population := uint(99)
+	sc := Scenario{
+		Name:  "wrong",
+		Rules: DefaultRules(),
+		Years: []ScenarioYear{{
+			Decision: Decision{GrainForFood: 2000, AcresToPlant: 1000},
+			Expect:   Expectation{Population: &population},
+		}},
+	}
+	err := sc.Run()
+	c.Check(errors.Is(err, ErrScenarioMismatch), Equals, true)
+	c.Check(err, ErrorMatches, ".*population is 103, expected 99")
+
+	// An impossible decision fails a strict scenario
+	sc.Strict = true
+	sc.Years[0].Decision.GrainForFood = 5000
+	c.Check(errors.Is(sc.Run(), ErrNotEnoughGrain), Equals, true)
+}
@@ -0,0 +1,47 @@
+package kingdomstate
+
+import (
+	"math/rand"
+)
+
+// SplitMix64 (Steele, Lea and Flood, 2014) is used both to derive seeds and as
+// a source of random numbers, since it is fast to seed and every seed gives
+// an independent stream.
+
+const golden = 0x9e3779b97f4a7c15
+
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// SubSeed derives the seed of the i'th game (or worker, or any other stream)
+// from a base seed, so that a batch of games can be reproduced from one seed
+// however it is shared out.
+func SubSeed(seed int64, i uint64) int64 {
+	return int64(mix64(uint64(seed) + (i+1)*golden))
+}
+
+type splitMix64 struct {
+	state uint64
+}
+
+// NewSource returns a source of random numbers that is cheap to seed and
+// reseed, for use when every game gets its own seed.
+func NewSource(seed int64) rand.Source64 {
+	return &splitMix64{uint64(seed)}
+}
+
+func (s *splitMix64) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *splitMix64) Uint64() uint64 {
+	s.state += golden
+	return mix64(s.state)
+}
+
+func (s *splitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
@@ -0,0 +1,50 @@
+package kingdomstate
+
+import (
+	"math/rand"
+
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestSubSeed(c *C) {
+	seen := make(map[int64]bool)
+	for i := uint64(0); i < 1000; i++ {
+		seed := SubSeed(99, i)
+		c.Check(seen[seed], Equals, false)
+		c.Check(SubSeed(99, i), Equals, seed)
+		seen[seed] = true
+	}
+	c.Check(SubSeed(99, 0) == SubSeed(100, 0), Equals, false)
+}
+
+func (s *S) TestNewSource(c *C) {
+	a := rand.New(NewSource(5))
+	b := rand.New(NewSource(5))
+	for i := 0; i < 100; i++ {
+		c.Check(a.Int63(), Equals, b.Int63())
+	}
+
+	first := a.Int63()
+	a.Seed(5)
+	b.Seed(5)
+	c.Check(a.Int63(), Equals, b.Int63())
+	c.Check(rand.New(NewSource(6)).Int63() == first, Equals, false)
+
+	totals := make(map[int]int)
+	for i := 0; i < 10000; i++ {
+		totals[a.Intn(10)]++
+	}
+	for i := 0; i < 10; i++ {
+		c.Check(totals[i], IntegerBetween, 800, 1200)
+	}
+}
+
+func (s *S) TestSeededGamesMatch(c *C) {
+	var seeded, unseeded KingdomState
+
+	seeded.SetupSeededState(DefaultRules(), 123)
+	unseeded.SetupInitialState(DefaultRules(), NewRandomEvents(DefaultRules(), rand.New(NewSource(123))))
+	RunGame(&seeded, FeedThenPlantStrategy{})
+	RunGame(&unseeded, FeedThenPlantStrategy{})
+	c.Check(seeded.LastReport(), Equals, unseeded.LastReport())
+}
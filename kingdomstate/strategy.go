@@ -0,0 +1,100 @@
+package kingdomstate
+
+import (
+	"math/rand"
+)
+
+// A Strategy decides how to rule the kingdom each year. It is handed a copy
+// of the current state, so it may inspect but not alter the game in
+// progress; it must not call TallyUpYear on that copy.
+type Strategy interface {
+	Decide(ks KingdomState) Decision
+}
+
+// RunGame plays the kingdom with the given strategy until the ruler leaves office.
+func RunGame(ks *KingdomState, strategy Strategy) {
+	for ks.StillInOffice() {
+		d := strategy.Decide(*ks)
+		ks.TallyUpYear(d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant)
+	}
+}
+
+// MeanScore is the mean rating score (see Rating.Score) of the strategy over
+// a number of games. The i'th game is seeded with SubSeed(seed, i), so
+// strategies measured with the same seed face the same random events.
+func MeanScore(rules Rules, strategy Strategy, games int, seed int64) float64 {
+	var total float64
+	randgen := rand.New(NewSource(seed))
+	events := NewRandomEvents(rules, randgen)
+	for i := 0; i < games; i++ {
+		var ks KingdomState
+
+		randgen.Seed(SubSeed(seed, uint64(i)))
+		ks.SetupInitialState(rules, events)
+		RunGame(&ks, strategy)
+		total += ks.Evaluate().Score()
+	}
+	return total / float64(games)
+}
+
+// FixedStrategy makes the same decision every year.
+type FixedStrategy struct {
+	Decision Decision
+}
+
+func (s FixedStrategy) Decide(ks KingdomState) Decision {
+	return s.Decision
+}
+
+// FeedThenPlantStrategy feeds everyone it can and then plants as many acres
+// as the remaining grain and people allow. It never trades land.
+type FeedThenPlantStrategy struct{}
+
+func (s FeedThenPlantStrategy) Decide(ks KingdomState) Decision {
+	food := min(ks.population*ks.rules.GrainPerPerson, ks.grain)
+	return Decision{
+		GrainForFood: food,
+		AcresToPlant: ks.acresPlantable(ks.grain-food, ks.acreage),
+	}
+}
+
+// BuyLowSellHighStrategy feeds and plants like FeedThenPlantStrategy, but
+// buys land the people could work when the price is at or below BuyBelow, and
+// sells land they cannot work when the price is at or above SellAbove.
+type BuyLowSellHighStrategy struct {
+	BuyBelow  uint
+	SellAbove uint
+}
+
+func (s BuyLowSellHighStrategy) Decide(ks KingdomState) Decision {
+	price := ks.nextYearPricePerAcre
+	bid, ask := ks.bidAsk(price)
+	seedRatio := ks.rules.AcresPerBushel
+	workable := ks.Workers() * ks.rules.AcresPerPerson
+	food := min(ks.population*ks.rules.GrainPerPerson, ks.grain)
+	grain := ks.grain - food
+	acreage := ks.acreage
+
+	var d Decision
+	switch {
+	case price <= s.BuyBelow && acreage < workable:
+		// Spend whatever is not needed to seed the current fields, allowing
+		// for the seed each new acre will need
+		spare := grain - min(grain, min(acreage, workable)/seedRatio)
+		d.AcresToBuy = min(workable-acreage, spare*seedRatio/(ask*seedRatio+1))
+	case price >= s.SellAbove && acreage > workable:
+		d.AcresToSell = acreage - workable
+	}
+	grain = grain - d.AcresToBuy*ask + d.AcresToSell*bid
+	acreage = acreage + d.AcresToBuy - d.AcresToSell
+
+	d.GrainForFood = food
+	d.AcresToPlant = ks.acresPlantable(grain, acreage)
+	return d
+}
+
+// acresPlantable is the most of the given land that can be seeded with the
+// given grain and tended by the kingdom's workers.
+func (ks KingdomState) acresPlantable(grain, acreage uint) uint {
+	return min(min(grain*ks.rules.AcresPerBushel, acreage), ks.Workers()*ks.rules.AcresPerPerson)
+}
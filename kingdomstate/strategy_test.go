@@ -0,0 +1,79 @@
+package kingdomstate
+
+import (
+	. "github.com/go-check/check"
+)
+
+func (s *S) TestFixedStrategy(c *C) {
+	var ks KingdomState
+	d := Decision{AcresToSell: 50, GrainForFood: 2000, AcresToPlant: 10}
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	c.Check(FixedStrategy{d}.Decide(ks), Equals, d)
+}
+
+func (s *S) TestFeedThenPlantStrategy(c *C) {
+	var ks KingdomState
+
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	d := FeedThenPlantStrategy{}.Decide(ks)
+	c.Check(d, Equals, Decision{GrainForFood: 2000, AcresToPlant: 1000})
+
+	ks.grain = 1000
+	d = FeedThenPlantStrategy{}.Decide(ks)
+	c.Check(d, Equals, Decision{GrainForFood: 1000, AcresToPlant: 0})
+}
+
+func (s *S) TestBuyLowSellHighStrategy(c *C) {
+	var ks KingdomState
+	strategy := BuyLowSellHighStrategy{BuyBelow: 21, SellAbove: 24}
+
+	// 100 people can work 2000 acres, so buy with the 400 bushels left
+	// after feeding them and seeding the current 1000 acres
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	ks.grain = 2900
+	d := strategy.Decide(ks)
+	c.Check(d.AcresToBuy, Equals, uint(400*AcresPerBushel/(21*AcresPerBushel+1)))
+	c.Check(d.AcresToSell, Equals, uint(0))
+	c.Check(d.GrainForFood, Equals, uint(2000))
+	c.Check(d.AcresToPlant, Equals, 1000+d.AcresToBuy)
+	c.Check(d.AcresToBuy*21+d.GrainForFood+d.AcresToPlant/AcresPerBushel <= ks.grain, Equals, true)
+
+	// 10 people can only work 200 acres, so sell the rest when the price is high
+	ks.population = 10
+	ks.nextYearPricePerAcre = 25
+	d = strategy.Decide(ks)
+	c.Check(d, Equals, Decision{AcresToSell: 800, GrainForFood: 200, AcresToPlant: 200})
+
+	// but hold on to it at an average price
+	ks.nextYearPricePerAcre = 22
+	d = strategy.Decide(ks)
+	c.Check(d, Equals, Decision{GrainForFood: 200, AcresToPlant: 200})
+}
+
+func (s *S) TestRunGame(c *C) {
+	for i := 0; i < 100; i++ {
+		var ks KingdomState
+
+		ks.SetupInitialState(DefaultRules(), NewRandomEvents(DefaultRules(), randgen))
+		RunGame(&ks, FeedThenPlantStrategy{})
+		c.Check(ks.StillInOffice(), Equals, false)
+		c.Check(ks.YearOfRule() >= 1 && ks.YearOfRule() <= 10, Equals, true)
+	}
+}
+
+func (s *S) TestMeanScore(c *C) {
+	rules := DefaultRules()
+	c.Check(MeanScore(rules, FeedThenPlantStrategy{}, 20, 4), Equals, MeanScore(rules, FeedThenPlantStrategy{}, 20, 4))
+
+	// Each game is the one seeded with SubSeed(seed, i)
+	var total float64
+	for i := 0; i < 3; i++ {
+		var ks KingdomState
+
+		ks.SetupSeededState(rules, SubSeed(4, uint64(i)))
+		RunGame(&ks, FeedThenPlantStrategy{})
+		total += ks.Evaluate().Score()
+	}
+	c.Check(MeanScore(rules, FeedThenPlantStrategy{}, 3, 4), Equals, total/3)
+}
@@ -0,0 +1,258 @@
+package kingdomstate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Regime is the weather of a year.
+type Regime int
+
+const (
+	Normal Regime = iota
+	Drought
+	Abundant
+	regimes = iota
+)
+
+var regimeNames = []string{
+	Normal:   "normal",
+	Drought:  "drought",
+	Abundant: "abundant",
+}
+
+func (r Regime) String() string {
+	if r < 0 || int(r) >= len(regimeNames) {
+		return fmt.Sprintf("Regime(%d)", int(r))
+	}
+	return regimeNames[r]
+}
+
+func (r Regime) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Regime) UnmarshalText(text []byte) error {
+	for i, name := range regimeNames {
+		if string(text) == name {
+			*r = Regime(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown weather %q", text)
+}
+
+// RegimeRules describe the harvests of a weather regime and how likely each
+// regime is to follow it.
+type RegimeRules struct {
+	MinYieldPerAcre uint
+	MaxYieldPerAcre uint
+
+	ToNormal   float64
+	ToDrought  float64
+	ToAbundant float64
+}
+
+// next is the chance that the regime is followed by r.
+func (rr RegimeRules) next(r Regime) float64 {
+	switch r {
+	case Drought:
+		return rr.ToDrought
+	case Abundant:
+		return rr.ToAbundant
+	}
+	return rr.ToNormal
+}
+
+// WeatherRules describe the optional weather. Without it, each year's
+// harvest is drawn afresh. With it, the weather runs in spells of drought,
+// normal and abundant years, each regime with harvests of its own, and each
+// year's weather is forecast the year before.
+type WeatherRules struct {
+	Enabled bool
+
+	Normal   RegimeRules
+	Drought  RegimeRules
+	Abundant RegimeRules
+
+	Forecasts        bool    // whether the ruler is told the forecast
+	ForecastAccuracy float64 // chance the forecast is right; it is otherwise one of the other regimes, evenly
+}
+
+// DefaultWeatherRules are the weather's rules once enabled. Droughts and
+// abundant years tend to last a few years, and harvests average much as they
+// do without weather.
+func DefaultWeatherRules() WeatherRules {
+	return WeatherRules{
+		Normal:           RegimeRules{MinYieldPerAcre: 2, MaxYieldPerAcre: 4, ToNormal: 0.7, ToDrought: 0.15, ToAbundant: 0.15},
+		Drought:          RegimeRules{MinYieldPerAcre: 1, MaxYieldPerAcre: 2, ToNormal: 0.35, ToDrought: 0.6, ToAbundant: 0.05},
+		Abundant:         RegimeRules{MinYieldPerAcre: 4, MaxYieldPerAcre: 5, ToNormal: 0.35, ToDrought: 0.05, ToAbundant: 0.6},
+		Forecasts:        true,
+		ForecastAccuracy: 0.7,
+	}
+}
+
+// Regime returns the rules of a regime.
+func (w WeatherRules) Regime(r Regime) RegimeRules {
+	switch r {
+	case Drought:
+		return w.Drought
+	case Abundant:
+		return w.Abundant
+	}
+	return w.Normal
+}
+
+func (w WeatherRules) Validate() error {
+	for r := Regime(0); r < regimes; r++ {
+		rr := w.Regime(r)
+		if rr.MinYieldPerAcre > rr.MaxYieldPerAcre || rr.MaxYieldPerAcre > MaxPerAcre {
+			return fmt.Errorf("need MinYieldPerAcre <= MaxYieldPerAcre <= %d in Weather.%s", MaxPerAcre, regimeNames[r])
+		}
+		if rr.ToNormal < 0 || rr.ToDrought < 0 || rr.ToAbundant < 0 || rr.ToNormal+rr.ToDrought+rr.ToAbundant == 0 {
+			return fmt.Errorf("Weather.%s needs chances of what follows it that are not negative and not all zero", regimeNames[r])
+		}
+	}
+	if w.ForecastAccuracy < 0 || w.ForecastAccuracy > 1 {
+		return errors.New("Weather.ForecastAccuracy must be between 0 and 1")
+	}
+	return nil
+}
+
+// forecastChance is the chance of forecasting f when the weather will be r.
+func (w WeatherRules) forecastChance(f, r Regime) float64 {
+	if f == r {
+		return w.ForecastAccuracy
+	}
+	return (1 - w.ForecastAccuracy) / (regimes - 1)
+}
+
+// pick picks a regime with chances in proportion to the weights, given a
+// uniform draw u from [0, 1). It picks fallback if every weight is zero.
+func pick(u float64, weights [regimes]float64, fallback Regime) Regime {
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return fallback
+	}
+	u *= total
+	for r, w := range weights {
+		if u < w {
+			return Regime(r)
+		}
+		u -= w
+	}
+	return Regime(regimes - 1)
+}
+
+// WeatherSource decides the weather, for kingdoms whose rules enable it. The
+// forecast of each year is made first, during the year before; the weather
+// then follows, as likely to match the forecast as the rules say.
+type WeatherSource interface {
+	Forecast(year uint, last Regime) Regime         // forecast of the year's weather, given last year's
+	Regime(year uint, last, forecast Regime) Regime // the year's weather
+	YieldIn(year uint, weather Regime) uint         // bushels harvested per acre planted in the weather
+}
+
+// Forecast draws the forecast from its chances over every weather that might follow.
+func (e *RandomEvents) Forecast(year uint, last Regime) Regime {
+	w := e.rules.Weather
+	var chances [regimes]float64
+	for f := range chances {
+		for r := Regime(0); r < regimes; r++ {
+			chances[f] += w.Regime(last).next(r) * w.forecastChance(Regime(f), r)
+		}
+	}
+	return pick(e.randgen.Float64(), chances, last)
+}
+
+// Regime draws the weather from its chances given last year's and the forecast.
+func (e *RandomEvents) Regime(year uint, last, forecast Regime) Regime {
+	w := e.rules.Weather
+	var chances [regimes]float64
+	for r := range chances {
+		chances[r] = w.Regime(last).next(Regime(r)) * w.forecastChance(forecast, Regime(r))
+	}
+	return pick(e.randgen.Float64(), chances, forecast)
+}
+
+func (e *RandomEvents) YieldIn(year uint, weather Regime) uint {
+	rr := e.rules.Weather.Regime(weather)
+	return uint(e.randgen.Intn(int(rr.MaxYieldPerAcre-rr.MinYieldPerAcre+1))) + rr.MinYieldPerAcre
+}
+
+// FixedEvents always have normal weather, correctly forecast.
+func (FixedEvents) Forecast(year uint, last Regime) Regime         { return Normal }
+func (FixedEvents) Regime(year uint, last, forecast Regime) Regime { return forecast }
+func (e FixedEvents) YieldIn(year uint, weather Regime) uint       { return e.YieldPerAcre(year) }
+
+func (e ScriptedEvents) Forecast(year uint, last Regime) Regime {
+	if y, ok := e.year(year); ok {
+		return y.Forecast
+	}
+	return weatherSource(e.fallback()).Forecast(year, last)
+}
+
+func (e ScriptedEvents) Regime(year uint, last, forecast Regime) Regime {
+	if y, ok := e.year(year); ok {
+		return y.Regime
+	}
+	return weatherSource(e.fallback()).Regime(year, last, forecast)
+}
+
+// YieldIn is the scripted yield, whatever the weather.
+func (e ScriptedEvents) YieldIn(year uint, weather Regime) uint {
+	if y, ok := e.year(year); ok {
+		return y.YieldPerAcre
+	}
+	return weatherSource(e.fallback()).YieldIn(year, weather)
+}
+
+// weatherSource returns the source's weather, or FixedEvents' if it has none.
+func weatherSource(source EventSource) WeatherSource {
+	if w, ok := source.(WeatherSource); ok {
+		return w
+	}
+	return FixedEvents{}
+}
+
+func (e *RecordingEvents) Forecast(year uint, last Regime) Regime {
+	v := weatherSource(e.Source).Forecast(year, last)
+	e.year(year).Forecast = v
+	return v
+}
+
+func (e *RecordingEvents) Regime(year uint, last, forecast Regime) Regime {
+	v := weatherSource(e.Source).Regime(year, last, forecast)
+	e.year(year).Regime = v
+	return v
+}
+
+func (e *RecordingEvents) YieldIn(year uint, weather Regime) uint {
+	v := weatherSource(e.Source).YieldIn(year, weather)
+	e.year(year).YieldPerAcre = v
+	return v
+}
+
+// weather returns the source of the kingdom's weather, or nil if its rules have none.
+func (ks KingdomState) weather() WeatherSource {
+	if !ks.rules.Weather.Enabled {
+		return nil
+	}
+	return weatherSource(ks.events)
+}
+
+// Regime is last year's weather. It is always normal if the rules have no weather.
+func (ks KingdomState) Regime() Regime {
+	return ks.regime
+}
+
+// Forecast is the forecast of this year's weather, if the rules give one.
+func (ks KingdomState) Forecast() (Regime, bool) {
+	if !ks.rules.Weather.Enabled || !ks.rules.Weather.Forecasts {
+		return Normal, false
+	}
+	return ks.forecast, true
+}
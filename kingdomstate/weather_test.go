@@ -0,0 +1,106 @@
+package kingdomstate
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/go-check/check"
+)
+
+func weatherRules() Rules {
+	rules := DefaultRules()
+	rules.Weather = DefaultWeatherRules()
+	rules.Weather.Enabled = true
+	return rules
+}
+
+func (s *S) TestRegimeText(c *C) {
+	for r := Regime(0); r < regimes; r++ {
+		text, err := r.MarshalText()
+		c.Assert(err, IsNil)
+		var back Regime
+		c.Check(back.UnmarshalText(text), IsNil)
+		c.Check(back, Equals, r)
+	}
+	var r Regime
+	c.Check(r.UnmarshalText([]byte("monsoon")), NotNil)
+}
+
+func (s *S) TestNoWeather(c *C) {
+	var ks KingdomState
+	ks.SetupInitialState(DefaultRules(), FixedEvents{})
+	_, ok := ks.Forecast()
+	c.Check(ok, Equals, false)
+	r, _ := ks.ApplyDecision(Decision{GrainForFood: 2000, AcresToPlant: 1000})
+	c.Check(r.Regime, Equals, Normal)
+	c.Check(ks.Regime(), Equals, Normal)
+}
+
+func (s *S) TestScriptedWeather(c *C) {
+	var ks KingdomState
+	events := ScriptedEvents{Years: []ScriptedYear{
+		{PricePerAcre: 20, YieldPerAcre: 1, Regime: Drought, Forecast: Abundant},
+		{PricePerAcre: 20, YieldPerAcre: 1, Regime: Drought, Forecast: Drought},
+	}}
+	ks.SetupInitialState(weatherRules(), events)
+	forecast, ok := ks.Forecast()
+	c.Check(ok, Equals, true)
+	c.Check(forecast, Equals, Abundant)
+
+	r, _ := ks.ApplyDecision(Decision{GrainForFood: 2000, AcresToPlant: 1000})
+	c.Check(r.Regime, Equals, Drought)
+	c.Check(r.HarvestPerAcre, Equals, uint(1))
+	c.Check(r.Forecast, Equals, Drought)
+	c.Check(ks.Regime(), Equals, Drought)
+
+	// The forecast is kept from rulers when the rules say so
+	rules := weatherRules()
+	rules.Weather.Forecasts = false
+	ks.SetupInitialState(rules, events)
+	_, ok = ks.Forecast()
+	c.Check(ok, Equals, false)
+}
+
+func (s *S) TestRandomWeather(c *C) {
+	rules := weatherRules()
+	events := NewRandomEvents(rules, rand.New(NewSource(1)))
+
+	var stays, droughts, right, years int
+	last := Normal
+	for year := uint(1); year <= 100000; year++ {
+		forecast := events.Forecast(year, last)
+		weather := events.Regime(year, last, forecast)
+		yield := events.YieldIn(year, weather)
+		rr := rules.Weather.Regime(weather)
+		c.Assert(yield >= rr.MinYieldPerAcre && yield <= rr.MaxYieldPerAcre, Equals, true)
+
+		if last == Drought {
+			droughts++
+			if weather == Drought {
+				stays++
+			}
+		}
+		if forecast == weather {
+			right++
+		}
+		years++
+		last = weather
+	}
+	c.Check(math.Abs(float64(stays)/float64(droughts)-rules.Weather.Drought.ToDrought) < 0.02, Equals, true,
+		Commentf("droughts lasted %d of %d times", stays, droughts))
+	c.Check(math.Abs(float64(right)/float64(years)-rules.Weather.ForecastAccuracy) < 0.01, Equals, true,
+		Commentf("forecast right %d of %d times", right, years))
+}
+
+func (s *S) TestRecordedWeatherReplays(c *C) {
+	var ks KingdomState
+	rules := weatherRules()
+	events := NewRecordingEvents(NewRandomEvents(rules, rand.New(NewSource(3))))
+	ks.SetupInitialState(rules, events)
+	RunGame(&ks, FeedThenPlantStrategy{})
+
+	var again KingdomState
+	again.SetupInitialState(rules, events.Script())
+	RunGame(&again, FeedThenPlantStrategy{})
+	c.Check(again.LastReport(), DeepEquals, ks.LastReport())
+}
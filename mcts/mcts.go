@@ -0,0 +1,214 @@
+// Package mcts rules the kingdom by Monte Carlo tree search.
+//
+// The search is open loop: a node of the tree is a sequence of actions from
+// the current year, not a state of the kingdom, since the same actions lead
+// to different kingdoms in different random futures. Each iteration plays a
+// clone of the kingdom into a fresh random future, choosing actions down the
+// tree by UCB1, adds a node for the first untried action, plays the rest of
+// the term with a rollout strategy, and credits the final score to every
+// node on the way.
+package mcts
+
+import (
+	"gomurabi/kingdomstate"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// An Action is a candidate decision, in proportion to the kingdom it is made in.
+type Action struct {
+	Trade float64 // share of the acreage to buy, if positive, or sell, if negative
+	Food  float64 // share of the people's needs to feed them
+}
+
+// DefaultActions trade up to a fifth of the land and feed at least three
+// quarters of the people.
+func DefaultActions() []Action {
+	var actions []Action
+	for _, trade := range []float64{-0.2, -0.1, 0, 0.1, 0.2} {
+		for _, food := range []float64{1, 0.9, 0.75} {
+			actions = append(actions, Action{trade, food})
+		}
+	}
+	return actions
+}
+
+// Decision turns the action into orders for the kingdom, planting all it can.
+func (a Action) Decision(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	rules := ks.Rules()
+	bid, ask := ks.NextYearBidPerAcre(), ks.NextYearAskPerAcre()
+	population := ks.Population()
+	grain := ks.Grain()
+	acreage := ks.Acreage()
+
+	var d kingdomstate.Decision
+	switch {
+	case a.Trade > 0 && ask > 0:
+		d.AcresToBuy = min(uint(a.Trade*float64(acreage)), grain/ask)
+	case a.Trade < 0:
+		d.AcresToSell = min(uint(-a.Trade*float64(acreage)), acreage)
+	}
+	grain = grain - d.AcresToBuy*ask + d.AcresToSell*bid
+	acreage = acreage + d.AcresToBuy - d.AcresToSell
+
+	d.GrainForFood = min(uint(a.Food*float64(population*rules.GrainPerPerson)), grain)
+	grain -= d.GrainForFood
+	d.AcresToPlant = min(min(grain*rules.AcresPerBushel, acreage), ks.Workers()*rules.AcresPerPerson)
+	return d
+}
+
+func min(i, j uint) uint {
+	if i < j {
+		return i
+	}
+	return j
+}
+
+// Config holds the parameters of the search.
+type Config struct {
+	Actions     []Action
+	Iterations  int                   // iterations of the search for each decision
+	Threads     int                   // each searches its share of the iterations with its own tree
+	Exploration float64               // UCB1 exploration constant, in points of score
+	Rollout     kingdomstate.Strategy // plays out the term beyond the tree
+	Seed        int64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Actions:     DefaultActions(),
+		Iterations:  500,
+		Threads:     1,
+		Exploration: 5,
+		Rollout:     kingdomstate.FeedThenPlantStrategy{},
+		Seed:        1,
+	}
+}
+
+// Player implements kingdomstate.Strategy by searching before every decision.
+type Player struct {
+	Config Config
+}
+
+// ActionStats is how an action at the root of the search fared.
+type ActionStats struct {
+	Action    Action
+	Visits    int
+	MeanScore float64
+}
+
+// node is a sequence of actions in the search tree.
+type node struct {
+	children []*node // by action, made when the node is first descended through
+	visits   int
+	total    float64
+}
+
+// ucb picks the child to descend to, trying every action once first.
+func (n *node) ucb(exploration float64) int {
+	best, bestValue := 0, math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+	for i, child := range n.children {
+		if child == nil || child.visits == 0 {
+			return i
+		}
+		value := child.total/float64(child.visits) + exploration*math.Sqrt(logVisits/float64(child.visits))
+		if value > bestValue {
+			best, bestValue = i, value
+		}
+	}
+	return best
+}
+
+// Decide implements kingdomstate.Strategy.
+func (p Player) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	stats := p.Search(ks)
+	best := 0
+	for i, s := range stats {
+		if s.Visits > stats[best].Visits {
+			best = i
+		}
+	}
+	return stats[best].Action.Decision(ks)
+}
+
+// Search searches the kingdom's future and reports how each action fared.
+// The search is seeded from Config.Seed and the kingdom, so it always
+// reaches the same verdict on the same kingdom.
+func (p Player) Search(ks kingdomstate.KingdomState) []ActionStats {
+	cfg := p.Config
+	threads := cfg.Threads
+	if threads < 1 {
+		threads = 1
+	}
+	seed := kingdomstate.SubSeed(cfg.Seed, uint64(ks.YearOfRule()))
+	for _, v := range []uint{ks.Population(), ks.Acreage(), ks.Grain(), ks.NextYearPricePerAcre()} {
+		seed = kingdomstate.SubSeed(seed, uint64(v))
+	}
+
+	var wg sync.WaitGroup
+	roots := make([]*node, threads)
+	for t := 0; t < threads; t++ {
+		roots[t] = &node{}
+		iterations := (t+1)*cfg.Iterations/threads - t*cfg.Iterations/threads
+		wg.Add(1)
+		go p.search(&wg, ks, kingdomstate.SubSeed(seed, uint64(t)), iterations, roots[t])
+	}
+	wg.Wait()
+
+	stats := make([]ActionStats, len(cfg.Actions))
+	for i, a := range cfg.Actions {
+		var total float64
+		stats[i].Action = a
+		for _, root := range roots {
+			if root.children == nil || root.children[i] == nil {
+				continue
+			}
+			stats[i].Visits += root.children[i].visits
+			total += root.children[i].total
+		}
+		if stats[i].Visits > 0 {
+			stats[i].MeanScore = total / float64(stats[i].Visits)
+		}
+	}
+	return stats
+}
+
+// search runs iterations of the search from the kingdom into the tree at root.
+func (p Player) search(wg *sync.WaitGroup, ks kingdomstate.KingdomState, seed int64, iterations int, root *node) {
+	cfg := p.Config
+	randgen := rand.New(kingdomstate.NewSource(seed))
+	events := kingdomstate.NewRandomEvents(ks.Rules(), randgen)
+	path := make([]*node, 0, ks.Rules().TermYears+1)
+	for it := 0; it < iterations; it++ {
+		future := ks.Clone(events)
+		n := root
+		path = append(path[:0], n)
+		for future.StillInOffice() {
+			if n.children == nil {
+				n.children = make([]*node, len(cfg.Actions))
+			}
+			i := n.ucb(cfg.Exploration)
+			if n.children[i] == nil {
+				n.children[i] = &node{}
+			}
+			expanding := n.children[i].visits == 0
+			d := cfg.Actions[i].Decision(future)
+			future.TallyUpYear(d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant)
+			n = n.children[i]
+			path = append(path, n)
+			if expanding {
+				break
+			}
+		}
+		kingdomstate.RunGame(&future, cfg.Rollout)
+
+		score := future.Evaluate().Score()
+		for _, n := range path {
+			n.visits++
+			n.total += score
+		}
+	}
+	wg.Done()
+}
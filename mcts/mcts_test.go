@@ -0,0 +1,66 @@
+package mcts
+
+import (
+	"gomurabi/kingdomstate"
+	"testing"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (s *S) TestActionDecision(c *C) {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupInitialState(kingdomstate.DefaultRules(), kingdomstate.FixedEvents{})
+	c.Check(Action{0, 1}.Decision(ks), Equals, kingdomstate.Decision{GrainForFood: 2000, AcresToPlant: 1000})
+	c.Check(Action{-0.1, 1}.Decision(ks), Equals, kingdomstate.Decision{AcresToSell: 100, GrainForFood: 2000, AcresToPlant: 900})
+	d := Action{0.05, 0.75}.Decision(ks)
+	c.Check(d, Equals, kingdomstate.Decision{AcresToBuy: 50, GrainForFood: 1500, AcresToPlant: 500})
+	c.Check(kingdomstate.ValidateDecision(ks, d), IsNil)
+
+	// Buy only what the grain pays for
+	d = Action{1, 1}.Decision(ks)
+	c.Check(d.AcresToBuy, Equals, uint(2800/21))
+	c.Check(kingdomstate.ValidateDecision(ks, d), IsNil)
+
+	// and buy nothing in a kingdom that was never set up
+	c.Check(Action{1, 1}.Decision(kingdomstate.KingdomState{}), Equals, kingdomstate.Decision{})
+}
+
+func (s *S) TestSearch(c *C) {
+	var ks kingdomstate.KingdomState
+
+	cfg := DefaultConfig()
+	cfg.Iterations = 300
+	cfg.Threads = 3
+	ks.SetupSeededState(kingdomstate.DefaultRules(), 4)
+	stats := Player{cfg}.Search(ks)
+	c.Assert(stats, HasLen, len(cfg.Actions))
+	visits := 0
+	for _, s := range stats {
+		c.Check(s.Visits > 0, Equals, true)
+		visits += s.Visits
+	}
+	c.Check(visits, Equals, cfg.Iterations)
+
+	// The same kingdom gets the same verdict
+	c.Check(Player{cfg}.Search(ks), DeepEquals, stats)
+	h, _ := ks.History()
+	c.Check(h.Years, HasLen, 0)
+}
+
+func (s *S) TestPlayer(c *C) {
+	cfg := DefaultConfig()
+	cfg.Iterations = 100
+	fixed := kingdomstate.FixedStrategy{Decision: kingdomstate.Decision{AcresToSell: 50, GrainForFood: 2000, AcresToPlant: 10}}
+	rules := kingdomstate.DefaultRules()
+	score := kingdomstate.MeanScore(rules, Player{cfg}, 50, 1)
+	c.Check(score > kingdomstate.MeanScore(rules, fixed, 50, 1), Equals, true)
+	c.Check(score >= kingdomstate.MeanScore(rules, cfg.Rollout, 50, 1), Equals, true)
+}
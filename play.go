@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// askUint asks until it gets a number, returning an error if the input ends first.
+func askUint(in *bufio.Reader, question string) (uint, error) {
+	for {
+		fmt.Print(question)
+		line, err := in.ReadString('\n')
+		if n, perr := strconv.ParseUint(strings.TrimSpace(line), 10, 32); perr == nil {
+			return uint(n), nil
+		}
+		if err != nil {
+			fmt.Printf("\nHammurabi: I cannot do what you wish.\nGet yourself another steward!!!!!\n")
+			return 0, err
+		}
+		fmt.Printf("Hammurabi: I do not understand. Now then,\n")
+	}
+}
+
+// play runs an interactive game, saving its history to historyFile if one is
+// given, even if the input ends before the game does.
+func play(rules kingdomstate.Rules, seed int64, historyFile string) error {
+	var ks kingdomstate.KingdomState
+
+	in := bufio.NewReader(os.Stdin)
+	var err error
+	ks.SetupSeededState(rules, seed)
+	defer func() {
+		if historyFile == "" {
+			return
+		}
+		h, _ := ks.History()
+		if err := saveHistory(historyFile, h); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot save history: %v\n", err)
+		}
+	}()
+	for ks.StillInOffice() {
+		ks.PrintSummary()
+		fmt.Printf("\n")
+
+		bid, ask := ks.NextYearBidPerAcre(), ks.NextYearAskPerAcre()
+		grain := ks.Grain()
+		acreage := ks.Acreage()
+		workers := ks.Workers()
+
+		// Buy land
+		var acresToBuy uint
+		for {
+			if acresToBuy, err = askUint(in, "How many acres do you wish to buy? "); err != nil {
+				return err
+			}
+			if acresToBuy*ask <= grain {
+				break
+			}
+			fmt.Printf("Hammurabi: Think again. You have only %d bushels of grain. Now then,\n", grain)
+		}
+		grain -= acresToBuy * ask
+		acreage += acresToBuy
+
+		// Sell land, but only if none was bought
+		var acresToSell uint
+		for acresToBuy == 0 {
+			if acresToSell, err = askUint(in, "How many acres do you wish to sell? "); err != nil {
+				return err
+			}
+			if acresToSell <= acreage {
+				break
+			}
+			fmt.Printf("Hammurabi: Think again. You own only %d acres. Now then,\n", acreage)
+		}
+		grain += acresToSell * bid
+		acreage -= acresToSell
+
+		// Feed the people
+		var grainForFood uint
+		for {
+			if grainForFood, err = askUint(in, "How many bushels do you wish to feed your people? "); err != nil {
+				return err
+			}
+			if grainForFood <= grain {
+				break
+			}
+			fmt.Printf("Hammurabi: Think again. You have only %d bushels of grain. Now then,\n", grain)
+		}
+		grain -= grainForFood
+
+		// Plant the fields
+		var acresToPlant uint
+		for {
+			if acresToPlant, err = askUint(in, "How many acres do you wish to plant with seed? "); err != nil {
+				return err
+			}
+			if acresToPlant > acreage {
+				fmt.Printf("Hammurabi: Think again. You own only %d acres. Now then,\n", acreage)
+			} else if acresToPlant/rules.AcresPerBushel > grain {
+				fmt.Printf("Hammurabi: Think again. You have only %d bushels of grain. Now then,\n", grain)
+			} else if acresToPlant > workers*rules.AcresPerPerson {
+				fmt.Printf("But you have only %d people to tend the fields! Now then,\n", workers)
+			} else {
+				break
+			}
+		}
+
+		ks.TallyUpYear(acresToBuy, acresToSell, grainForFood, acresToPlant)
+	}
+
+	ks.PrintSummary()
+	fmt.Printf("\n")
+	switch ks.GameOverReason() {
+	case kingdomstate.EveryoneDied:
+		fmt.Printf("Everyone in the kingdom has perished!!!\n")
+		printFink()
+		return nil
+	case kingdomstate.MassStarvation:
+		fmt.Printf("You starved %d people in one year!!!\n", ks.StarvationVictims())
+		printFink()
+		return nil
+	}
+	printEvaluation(ks.Evaluate(), rules.InitialAcreage/rules.InitialPopulation)
+	return nil
+}
+
+func printFink() {
+	fmt.Printf("Due to this extreme mismanagement you have not only\n")
+	fmt.Printf("been impeached and thrown out of office but you have\n")
+	fmt.Printf("also been declared national fink!!!!\n")
+}
+
+func printEvaluation(r kingdomstate.Rating, startingAcresPerPerson uint) {
+	fmt.Printf("In your %d-year term of office, %.0f percent of the\n", r.Years, r.PercentStarved)
+	fmt.Printf("population starved per year on the average, i.e. a total of\n")
+	fmt.Printf("%d people died!!\n", r.TotalStarved)
+	fmt.Printf("You started with %d acres per person and ended with\n", startingAcresPerPerson)
+	fmt.Printf("%.1f acres per person.\n\n", r.AcresPerPerson)
+
+	switch r.Tier {
+	case kingdomstate.NationalFink:
+		printFink()
+	case kingdomstate.Nero:
+		fmt.Printf("Your heavy-handed performance smacks of Nero and Ivan IV.\n")
+		fmt.Printf("The people (remaining) find you an unpleasant ruler, and,\n")
+		fmt.Printf("frankly, hate your guts!!\n")
+	case kingdomstate.Average:
+		fmt.Printf("Your performance could have been somewhat better, but\n")
+		fmt.Printf("really wasn't too bad at all. Many people would\n")
+		fmt.Printf("dearly like to see you assassinated but we all have our\n")
+		fmt.Printf("trivial problems.\n")
+	case kingdomstate.Charlemagne:
+		fmt.Printf("A fantastic performance!!! Charlemagne, Disraeli, and\n")
+		fmt.Printf("Jefferson combined could not have done better!\n")
+	}
+}
@@ -0,0 +1,63 @@
+package qlearn
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"gomurabi/env"
+	"gomurabi/kingdomstate"
+	"os"
+)
+
+// agentFile is how an agent is saved.
+type agentFile struct {
+	Rules   kingdomstate.Rules
+	Binning Binning
+	Actions []env.Action
+	Q       []float64
+}
+
+// Save writes the agent's Q-table to a gzipped JSON file.
+func (a *Agent) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	z := gzip.NewWriter(f)
+	err = json.NewEncoder(z).Encode(agentFile{a.rules, a.binning, a.actions, a.q})
+	if cerr := z.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Load reads an agent written by Save.
+func Load(filename string) (*Agent, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	z, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	var af agentFile
+	if err := json.NewDecoder(z).Decode(&af); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := af.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if len(af.Actions) == 0 {
+		return nil, fmt.Errorf("%s: no actions", filename)
+	}
+
+	a := newAgent(af.Rules, af.Binning, af.Actions)
+	if len(af.Q) != len(a.q) {
+		return nil, fmt.Errorf("%s: Q-table does not fit its states and actions", filename)
+	}
+	a.q = af.Q
+	return a, nil
+}
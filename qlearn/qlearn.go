@@ -0,0 +1,210 @@
+// Package qlearn learns to rule the kingdom by tabular Q-learning, playing
+// reigns in the env environment.
+package qlearn
+
+import (
+	"gomurabi/env"
+	"gomurabi/kingdomstate"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Binning is how observations are grouped into the states of the Q-table.
+// Each value falls in the bin after the last edge it reaches. The year and
+// the price of land each have a bin of their own for every value.
+type Binning struct {
+	Population     []float64
+	GrainPerPerson []float64
+	AcresPerPerson []float64
+}
+
+func DefaultBinning() Binning {
+	return Binning{
+		Population:     []float64{50, 80, 100, 120, 150},
+		GrainPerPerson: []float64{10, 20, 30, 40, 60},
+		AcresPerPerson: []float64{6, 8, 10, 12, 15, 20},
+	}
+}
+
+func bin(v float64, edges []float64) int {
+	return sort.Search(len(edges), func(i int) bool { return edges[i] > v })
+}
+
+// Schedule is a rate that decays from Start to End over the episodes of
+// training, linearly, or geometrically if Exponential.
+type Schedule struct {
+	Start       float64
+	End         float64
+	Exponential bool
+}
+
+// At is the rate once the given share of the training is done.
+func (s Schedule) At(progress float64) float64 {
+	if s.Exponential && s.Start > 0 && s.End > 0 {
+		return s.Start * math.Pow(s.End/s.Start, progress)
+	}
+	return s.Start + (s.End-s.Start)*progress
+}
+
+// Config holds the parameters of training.
+type Config struct {
+	Rules        kingdomstate.Rules
+	Binning      Binning
+	Actions      []env.Action
+	Rewards      env.Rewards
+	Episodes     int
+	Epsilon      Schedule // chance of exploring with a random action
+	LearningRate Schedule
+	Discount     float64
+	Seed         int64 // episode i is seeded with kingdomstate.SubSeed(Seed, i)
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Rules:        kingdomstate.DefaultRules(),
+		Binning:      DefaultBinning(),
+		Actions:      env.DefaultActions(),
+		Rewards:      env.DefaultRewards(),
+		Episodes:     200000,
+		Epsilon:      Schedule{Start: 1, End: 0.01, Exponential: true},
+		LearningRate: Schedule{Start: 0.2, End: 0.01, Exponential: true},
+		Discount:     1,
+		Seed:         1,
+	}
+}
+
+// Agent plays the greedy policy of a Q-table. It implements kingdomstate.Strategy.
+type Agent struct {
+	rules   kingdomstate.Rules
+	binning Binning
+	actions []env.Action
+	q       []float64 // by state, then action
+}
+
+func newAgent(rules kingdomstate.Rules, binning Binning, actions []env.Action) *Agent {
+	a := &Agent{rules: rules, binning: binning, actions: actions}
+	a.q = make([]float64, a.states()*len(actions))
+	return a
+}
+
+func (a *Agent) prices() int {
+	return int(a.rules.MaxPricePerAcre-a.rules.MinPricePerAcre) + 1
+}
+
+func (a *Agent) states() int {
+	b := a.binning
+	return int(a.rules.TermYears) * (len(b.Population) + 1) * (len(b.GrainPerPerson) + 1) * (len(b.AcresPerPerson) + 1) * a.prices()
+}
+
+// state is the Q-table state the observation falls in.
+func (a *Agent) state(o env.Observation) int {
+	b := a.binning
+	year := int(o.Year)
+	if year >= int(a.rules.TermYears) {
+		year = int(a.rules.TermYears) - 1
+	}
+	var grainPerPerson, acresPerPerson float64
+	if o.Population > 0 {
+		grainPerPerson = float64(o.Grain) / float64(o.Population)
+		acresPerPerson = float64(o.Acreage) / float64(o.Population)
+	}
+	price := 0
+	if o.PricePerAcre > a.rules.MinPricePerAcre {
+		price = int(o.PricePerAcre - a.rules.MinPricePerAcre)
+	}
+	if price >= a.prices() {
+		price = a.prices() - 1
+	}
+
+	s := year
+	s = s*(len(b.Population)+1) + bin(float64(o.Population), b.Population)
+	s = s*(len(b.GrainPerPerson)+1) + bin(grainPerPerson, b.GrainPerPerson)
+	s = s*(len(b.AcresPerPerson)+1) + bin(acresPerPerson, b.AcresPerPerson)
+	return s*a.prices() + price
+}
+
+// values returns the Q-values of the actions in the state.
+func (a *Agent) values(state int) []float64 {
+	n := len(a.actions)
+	return a.q[state*n : (state+1)*n]
+}
+
+// best is the action with the highest value in the state.
+func (a *Agent) best(state int) int {
+	values := a.values(state)
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Rules returns the rules the agent was trained under.
+func (a *Agent) Rules() kingdomstate.Rules {
+	return a.rules
+}
+
+// Decide implements kingdomstate.Strategy.
+func (a *Agent) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	return a.actions[a.best(a.state(env.Observe(ks)))].Decision(ks)
+}
+
+// Progress reports on training after each tenth of the episodes.
+type Progress struct {
+	Episodes     int
+	MeanReturn   float64 // over the episodes since the last report
+	Epsilon      float64
+	LearningRate float64
+}
+
+// Train learns a Q-table by playing Config.Episodes reigns, calling report,
+// if not nil, as it goes.
+func Train(cfg Config, report func(Progress)) *Agent {
+	agent := newAgent(cfg.Rules, cfg.Binning, cfg.Actions)
+	e := env.New(cfg.Rules)
+	e.Rewards = cfg.Rewards
+	e.Actions = cfg.Actions
+	randgen := rand.New(kingdomstate.NewSource(cfg.Seed))
+
+	var returns float64
+	played := 0
+	chunk := cfg.Episodes / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	for episode := 0; episode < cfg.Episodes; episode++ {
+		progress := float64(episode) / float64(cfg.Episodes)
+		epsilon := cfg.Epsilon.At(progress)
+		rate := cfg.LearningRate.At(progress)
+
+		o := e.Reset(kingdomstate.SubSeed(cfg.Seed, uint64(episode)))
+		for done := false; !done; {
+			s := agent.state(o)
+			action := agent.best(s)
+			if randgen.Float64() < epsilon {
+				action = randgen.Intn(len(cfg.Actions))
+			}
+			var reward float64
+			o, reward, done, _ = e.StepDiscrete(action)
+			returns += reward
+
+			target := reward
+			if !done {
+				next := agent.state(o)
+				target += cfg.Discount * agent.values(next)[agent.best(next)]
+			}
+			q := &agent.values(s)[action]
+			*q += rate * (target - *q)
+		}
+
+		played++
+		if report != nil && ((episode+1)%chunk == 0 || episode+1 == cfg.Episodes) {
+			report(Progress{episode + 1, returns / float64(played), epsilon, rate})
+			returns, played = 0, 0
+		}
+	}
+	return agent
+}
@@ -0,0 +1,76 @@
+package qlearn
+
+import (
+	"gomurabi/kingdomstate"
+	"math"
+	"path/filepath"
+	"testing"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (s *S) TestSchedule(c *C) {
+	linear := Schedule{Start: 1, End: 0}
+	c.Check(linear.At(0), Equals, 1.0)
+	c.Check(linear.At(0.25), Equals, 0.75)
+	c.Check(linear.At(1), Equals, 0.0)
+
+	exponential := Schedule{Start: 1, End: 0.01, Exponential: true}
+	c.Check(exponential.At(0), Equals, 1.0)
+	c.Check(math.Abs(exponential.At(0.5)-0.1) < 1e-12, Equals, true)
+	c.Check(math.Abs(exponential.At(1)-0.01) < 1e-12, Equals, true)
+}
+
+func (s *S) TestBin(c *C) {
+	edges := []float64{10, 20}
+	c.Check(bin(5, edges), Equals, 0)
+	c.Check(bin(10, edges), Equals, 1)
+	c.Check(bin(19.9, edges), Equals, 1)
+	c.Check(bin(20, edges), Equals, 2)
+	c.Check(bin(1000, edges), Equals, 2)
+}
+
+func (s *S) TestTrain(c *C) {
+	cfg := DefaultConfig()
+	cfg.Episodes = 50000
+	var reports []Progress
+	agent := Train(cfg, func(p Progress) { reports = append(reports, p) })
+
+	c.Assert(reports, HasLen, 10)
+	c.Check(reports[9].Episodes, Equals, cfg.Episodes)
+	c.Check(reports[9].MeanReturn > reports[0].MeanReturn, Equals, true)
+	c.Check(reports[9].Epsilon < reports[0].Epsilon, Equals, true)
+
+	score := kingdomstate.MeanScore(cfg.Rules, agent, 1000, 2)
+	baseline := kingdomstate.MeanScore(cfg.Rules, kingdomstate.FeedThenPlantStrategy{}, 1000, 2)
+	c.Check(score > baseline+5, Equals, true, Commentf("scored %.2f against %.2f", score, baseline))
+
+	// Training is reproducible
+	cfg.Episodes = 1000
+	c.Check(Train(cfg, nil).q, DeepEquals, Train(cfg, nil).q)
+}
+
+func (s *S) TestSaveAndLoad(c *C) {
+	cfg := DefaultConfig()
+	cfg.Episodes = 1000
+	agent := Train(cfg, nil)
+
+	filename := filepath.Join(c.MkDir(), "q.json.gz")
+	c.Assert(agent.Save(filename), IsNil)
+	loaded, err := Load(filename)
+	c.Assert(err, IsNil)
+	c.Check(loaded.Rules(), Equals, agent.Rules())
+	c.Check(loaded.binning, DeepEquals, agent.binning)
+	c.Check(loaded.actions, DeepEquals, agent.actions)
+	c.Check(loaded.q, DeepEquals, agent.q)
+
+	_, err = Load(filepath.Join(c.MkDir(), "missing"))
+	c.Check(err, NotNil)
+}
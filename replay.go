@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"os"
+)
+
+func saveHistory(filename string, h kingdomstate.GameHistory) error {
+	data, err := json.MarshalIndent(h, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func loadHistory(filename string) (kingdomstate.GameHistory, error) {
+	var h kingdomstate.GameHistory
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return h, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err
+}
+
+// replay checks that a saved game replays exactly and prints each year of it.
+func replay(filename string) error {
+	h, err := loadHistory(filename)
+	if err != nil {
+		return err
+	}
+	ks, err := kingdomstate.Replay(h)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Seed=%d\n", h.Seed)
+	for _, r := range append([]kingdomstate.YearReport{h.Initial}, h.Years...) {
+		d := r.Decision
+		fmt.Printf("Year %2d: buy=%d sell=%d feed=%d plant=%d -> population=%d acreage=%d grain=%d starved=%d plague=%d immigrants=%d price=%d\n",
+			r.Year, d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant,
+			r.EndOfYearPopulation, r.EndOfYearAcreage, r.EndOfYearGrain,
+			r.StarvationVictims, r.PlagueVictims, r.Immigrants, r.NextYearPricePerAcre)
+	}
+	fmt.Printf("Game over: %v\n", ks.GameOverReason())
+	return nil
+}
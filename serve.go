@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"gomurabi/server"
+	"net/http"
+	"os"
+	"time"
+)
+
+// runServe hosts up to maxGames games over HTTP at addr, forgetting each once
+// it goes unused for ttl.
+func runServe(rules kingdomstate.Rules, addr string, ttl time.Duration, maxGames int) {
+	store := server.NewStore(ttl, maxGames)
+	if ttl > 0 {
+		defer store.SweepEvery(ttl)()
+	}
+	fmt.Printf("Serving games on %s\n", addr)
+	if err := http.ListenAndServe(addr, server.New(store, rules)); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot serve: %v\n", err)
+		os.Exit(1)
+	}
+}
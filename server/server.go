@@ -0,0 +1,283 @@
+// Package server hosts games over an HTTP JSON API:
+//
+//	POST   /games                 start a game; the body may set "seed", "rules" and "strict"
+//	GET    /games/{id}            the state of the game
+//	DELETE /games/{id}            forget the game
+//	POST   /games/{id}/decisions  play a year with the decision in the body
+//	GET    /games/{id}/history    every year played so far
+//
+// Errors are reported as {"error": "..."} with a suitable status code.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gomurabi/export"
+	"gomurabi/kingdomstate"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Largest request body accepted.
+const maxBodyBytes = 1 << 20
+
+// State is the state of a game at the start of a year.
+type State struct {
+	ID             string                     `json:"id"`
+	Seed           int64                      `json:"seed"`
+	YearOfRule     uint                       `json:"year_of_rule"`
+	Population     uint                       `json:"population"`
+	Acreage        uint                       `json:"acreage"`
+	Grain          uint                       `json:"grain"`
+	PricePerAcre   uint                       `json:"price_per_acre"`
+	BidPerAcre     uint                       `json:"bid_per_acre"`
+	AskPerAcre     uint                       `json:"ask_per_acre"`
+	PriceHistory   []uint                     `json:"price_history"`
+	Regime         *kingdomstate.Regime       `json:"regime,omitempty"`   // last year's weather, if the rules have weather
+	Forecast       *kingdomstate.Regime       `json:"forecast,omitempty"` // of this year's, if the rules give one
+	Children       *uint                      `json:"children,omitempty"` // if the rules have demographics
+	Workers        *uint                      `json:"workers,omitempty"`
+	Elders         *uint                      `json:"elders,omitempty"`
+	StillInOffice  bool                       `json:"still_in_office"`
+	GameOverReason kingdomstate.RemovalReason `json:"game_over_reason"`
+	LastYear       export.Record              `json:"last_year"`
+}
+
+// Decision is a year's decision as sent to the server.
+type Decision struct {
+	AcresToBuy   uint `json:"acres_to_buy"`
+	AcresToSell  uint `json:"acres_to_sell"`
+	GrainForFood uint `json:"grain_for_food"`
+	AcresToPlant uint `json:"acres_to_plant"`
+}
+
+// DecisionResult is the outcome of a decision: the year it played out and the state after it.
+type DecisionResult struct {
+	Report export.Record `json:"report"`
+	State  State         `json:"state"`
+}
+
+// History is every year of a game so far.
+type History struct {
+	ID      string             `json:"id"`
+	Seed    int64              `json:"seed"`
+	Rules   kingdomstate.Rules `json:"rules"`
+	Initial export.Record      `json:"initial"`
+	Years   []export.Record    `json:"years"`
+}
+
+type createRequest struct {
+	Seed   *int64          `json:"seed"`
+	Rules  json.RawMessage `json:"rules"`
+	Strict bool            `json:"strict"`
+}
+
+// Server serves the games in a store. Rules given when a game is created
+// are applied over the server's rules.
+type Server struct {
+	store *Store
+	rules kingdomstate.Rules
+}
+
+func New(store *Store, rules kingdomstate.Rules) *Server {
+	return &Server{store: store, rules: rules}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "games" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	switch {
+	case len(parts) == 1:
+		if allow(w, r, http.MethodPost) {
+			s.create(w, r)
+		}
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if !s.store.delete(parts[1]) {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no game %q", parts[1]))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2:
+		if allow(w, r, http.MethodGet, http.MethodDelete) {
+			s.withGame(w, parts[1], func(g *game) {
+				writeJSON(w, http.StatusOK, state(parts[1], g.ks))
+			})
+		}
+	case parts[2] == "decisions":
+		if allow(w, r, http.MethodPost) {
+			s.decide(w, r, parts[1])
+		}
+	case parts[2] == "history":
+		if allow(w, r, http.MethodGet) {
+			s.withGame(w, parts[1], func(g *game) {
+				writeJSON(w, http.StatusOK, history(parts[1], g.ks))
+			})
+		}
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// allow checks that the request uses one of the methods, and reports an error if not.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := readJSON(r, &req); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	rules := s.rules
+	if len(req.Rules) > 0 {
+		if err := json.Unmarshal(req.Rules, &rules); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := rules.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	id, g, err := s.store.create(rules, seed, req.Strict)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	writeJSON(w, http.StatusCreated, state(id, g.ks))
+}
+
+func (s *Server) decide(w http.ResponseWriter, r *http.Request, id string) {
+	g, ok := s.store.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no game %q", id))
+		return
+	}
+	var d Decision
+	if err := readJSON(r, &d); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	lock(g, func() {
+		report, err := g.ks.ApplyDecision(kingdomstate.Decision{
+			AcresToBuy:   d.AcresToBuy,
+			AcresToSell:  d.AcresToSell,
+			GrainForFood: d.GrainForFood,
+			AcresToPlant: d.AcresToPlant,
+		})
+		switch {
+		case errors.Is(err, kingdomstate.ErrGameOver):
+			writeError(w, http.StatusConflict, err)
+		case err != nil:
+			writeError(w, http.StatusUnprocessableEntity, err)
+		default:
+			writeJSON(w, http.StatusOK, DecisionResult{export.NewRecord(0, seedOf(g.ks), report), state(id, g.ks)})
+		}
+	})
+}
+
+// withGame calls f with the game locked, or reports that there is no such game.
+func (s *Server) withGame(w http.ResponseWriter, id string, f func(g *game)) {
+	g, ok := s.store.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no game %q", id))
+		return
+	}
+	lock(g, func() { f(g) })
+}
+
+// lock calls f with the game locked.
+func lock(g *game, f func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f()
+}
+
+func seedOf(ks kingdomstate.KingdomState) int64 {
+	h, _ := ks.History()
+	return h.Seed
+}
+
+func state(id string, ks kingdomstate.KingdomState) State {
+	seed := seedOf(ks)
+	s := State{
+		ID:             id,
+		Seed:           seed,
+		YearOfRule:     ks.YearOfRule(),
+		Population:     ks.Population(),
+		Acreage:        ks.Acreage(),
+		Grain:          ks.Grain(),
+		PricePerAcre:   ks.NextYearPricePerAcre(),
+		BidPerAcre:     ks.NextYearBidPerAcre(),
+		AskPerAcre:     ks.NextYearAskPerAcre(),
+		PriceHistory:   ks.PriceHistory(),
+		StillInOffice:  ks.StillInOffice(),
+		GameOverReason: ks.GameOverReason(),
+		LastYear:       export.NewRecord(0, seed, ks.LastReport()),
+	}
+	if ks.Rules().Weather.Enabled {
+		regime := ks.Regime()
+		s.Regime = &regime
+	}
+	if forecast, ok := ks.Forecast(); ok {
+		s.Forecast = &forecast
+	}
+	if ks.Rules().Demographics.Enabled {
+		children, workers, elders := ks.Children(), ks.Workers(), ks.Elders()
+		s.Children, s.Workers, s.Elders = &children, &workers, &elders
+	}
+	return s
+}
+
+func history(id string, ks kingdomstate.KingdomState) History {
+	h, _ := ks.History()
+	records := export.Records(0, h)
+	if records == nil {
+		records = []export.Record{}
+	}
+	return History{
+		ID:      id,
+		Seed:    h.Seed,
+		Rules:   h.Rules,
+		Initial: export.NewRecord(0, h.Seed, h.Initial),
+		Years:   records,
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"gomurabi/kingdomstate"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+// call makes a request of the server and decodes the response into v, if not nil, returning the status.
+func call(c *C, h http.Handler, method, path, body string, v interface{}) int {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if v != nil {
+		c.Assert(json.Unmarshal(w.Body.Bytes(), v), IsNil, Commentf("%s %s: %s", method, path, w.Body))
+	}
+	return w.Code
+}
+
+func (s *S) TestPlayGame(c *C) {
+	h := New(NewStore(time.Hour, 100), kingdomstate.DefaultRules())
+
+	var state State
+	c.Assert(call(c, h, "POST", "/games", `{"seed": 7}`, &state), Equals, http.StatusCreated)
+	c.Check(state.Seed, Equals, int64(7))
+	c.Check(state.YearOfRule, Equals, uint(0))
+	c.Check(state.Population, Equals, uint(100))
+	c.Check(state.StillInOffice, Equals, true)
+
+	// The game plays out as it would locally
+	var ks kingdomstate.KingdomState
+	ks.SetupSeededState(kingdomstate.DefaultRules(), 7)
+	for ks.StillInOffice() {
+		d := kingdomstate.FeedThenPlantStrategy{}.Decide(ks)
+		want, _ := ks.TallyUpYear(d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant)
+
+		var result DecisionResult
+		body, _ := json.Marshal(Decision{d.AcresToBuy, d.AcresToSell, d.GrainForFood, d.AcresToPlant})
+		c.Assert(call(c, h, "POST", "/games/"+state.ID+"/decisions", string(body), &result), Equals, http.StatusOK)
+		c.Check(result.Report.Year, Equals, want.Year)
+		c.Check(result.Report.Population, Equals, want.EndOfYearPopulation)
+		c.Check(result.Report.Grain, Equals, want.EndOfYearGrain)
+		c.Check(result.State.Acreage, Equals, ks.Acreage())
+		c.Check(result.State.PricePerAcre, Equals, ks.NextYearPricePerAcre())
+		c.Check(result.State.LastYear, Equals, result.Report)
+	}
+
+	c.Check(call(c, h, "GET", "/games/"+state.ID, "", &state), Equals, http.StatusOK)
+	c.Check(state.StillInOffice, Equals, false)
+	c.Check(state.GameOverReason, Equals, ks.GameOverReason())
+
+	var history History
+	c.Check(call(c, h, "GET", "/games/"+state.ID+"/history", "", &history), Equals, http.StatusOK)
+	c.Check(history.Years, HasLen, int(ks.YearOfRule()))
+	c.Check(history.Rules, Equals, kingdomstate.DefaultRules())
+
+	c.Check(call(c, h, "POST", "/games/"+state.ID+"/decisions", `{}`, nil), Equals, http.StatusConflict)
+	c.Check(call(c, h, "DELETE", "/games/"+state.ID, "", nil), Equals, http.StatusNoContent)
+	c.Check(call(c, h, "GET", "/games/"+state.ID, "", nil), Equals, http.StatusNotFound)
+}
+
+func (s *S) TestCreateWithRules(c *C) {
+	h := New(NewStore(time.Hour, 100), kingdomstate.DefaultRules())
+
+	var state State
+	c.Assert(call(c, h, "POST", "/games", `{"rules": {"InitialPopulation": 50}}`, &state), Equals, http.StatusCreated)
+	c.Check(state.Population, Equals, uint(50))
+	var history History
+	call(c, h, "GET", "/games/"+state.ID+"/history", "", &history)
+	c.Check(history.Rules.InitialAcreage, Equals, kingdomstate.DefaultRules().InitialAcreage)
+	c.Check(history.Years, HasLen, 0)
+
+	c.Check(state.Regime, IsNil)
+	c.Check(state.Forecast, IsNil)
+
+	// The weather is reported when the rules have it
+	c.Assert(call(c, h, "POST", "/games", `{"rules": {"Weather": {"Enabled": true}}}`, &state), Equals, http.StatusCreated)
+	c.Check(state.Regime, NotNil)
+	c.Check(state.Forecast, NotNil)
+	c.Check(state.Workers, IsNil)
+
+	// and so are the cohorts
+	c.Assert(call(c, h, "POST", "/games", `{"rules": {"Demographics": {"Enabled": true}}}`, &state), Equals, http.StatusCreated)
+	c.Assert(state.Workers, NotNil)
+	c.Check(*state.Children+*state.Workers+*state.Elders, Equals, state.Population)
+
+	c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusCreated)
+	c.Check(call(c, h, "POST", "/games", `{"rules": {"TermYears": 0}}`, nil), Equals, http.StatusBadRequest)
+	c.Check(call(c, h, "POST", "/games", `{"seed": "x"}`, nil), Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestCreateOverNestedRules(c *C) {
+	rules := kingdomstate.DefaultRules()
+	rules.Market.Enabled = true
+	rules.Market.ReversionPercent = 10
+	h := New(NewStore(time.Hour, 100), rules)
+
+	// Nested rules given are applied over the server's, not over the defaults
+	var state State
+	c.Assert(call(c, h, "POST", "/games", `{"rules": {"Market": {"Spread": 6}}}`, &state), Equals, http.StatusCreated)
+	var history History
+	call(c, h, "GET", "/games/"+state.ID+"/history", "", &history)
+	rules.Market.Spread = 6
+	c.Check(history.Rules.Market, Equals, rules.Market)
+}
+
+func (s *S) TestErrors(c *C) {
+	h := New(NewStore(time.Hour, 100), kingdomstate.DefaultRules())
+
+	var e map[string]string
+	c.Check(call(c, h, "GET", "/games/nope", "", &e), Equals, http.StatusNotFound)
+	c.Check(e["error"], Not(Equals), "")
+	c.Check(call(c, h, "GET", "/elsewhere", "", nil), Equals, http.StatusNotFound)
+	c.Check(call(c, h, "GET", "/games", "", nil), Equals, http.StatusMethodNotAllowed)
+	c.Check(call(c, h, "POST", "/games/nope/decisions", `{"plant": 10}`, nil), Equals, http.StatusNotFound)
+
+	var state State
+	call(c, h, "POST", "/games", `{"strict": true}`, &state)
+	path := "/games/" + state.ID + "/decisions"
+	c.Check(call(c, h, "POST", path, `{"acres_to_plant": 1000000}`, &e), Equals, http.StatusUnprocessableEntity)
+	c.Check(call(c, h, "POST", path, `{"acres_to_plant": -1}`, nil), Equals, http.StatusBadRequest)
+	c.Check(call(c, h, "POST", path, `{"plant": 10}`, nil), Equals, http.StatusBadRequest)
+	c.Check(call(c, h, "GET", "/games/"+state.ID, "", &state), Equals, http.StatusOK)
+	c.Check(state.YearOfRule, Equals, uint(0))
+}
+
+func (s *S) TestExpiry(c *C) {
+	store := NewStore(time.Minute, 100)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+
+	old, _, _ := store.create(kingdomstate.DefaultRules(), 1, false)
+	used, _, _ := store.create(kingdomstate.DefaultRules(), 2, false)
+	now = now.Add(50 * time.Second)
+	_, ok := store.get(used)
+	c.Check(ok, Equals, true)
+
+	now = now.Add(20 * time.Second)
+	_, ok = store.get(old)
+	c.Check(ok, Equals, false)
+	c.Check(store.Len(), Equals, 1)
+
+	// Creating a game sweeps out the others that expired
+	now = now.Add(2 * time.Minute)
+	store.create(kingdomstate.DefaultRules(), 3, false)
+	c.Check(store.Len(), Equals, 1)
+	_, ok = store.get(used)
+	c.Check(ok, Equals, false)
+}
+
+func (s *S) TestFull(c *C) {
+	store := NewStore(time.Minute, 2)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+	h := New(store, kingdomstate.DefaultRules())
+
+	c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusCreated)
+	c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusCreated)
+	c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusServiceUnavailable)
+	c.Check(store.Len(), Equals, 2)
+
+	// Once the games expire there is room again
+	now = now.Add(2 * time.Minute)
+	c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusCreated)
+	c.Check(store.Len(), Equals, 1)
+}
+
+func (s *S) TestNoLimits(c *C) {
+	store := NewStore(0, 0)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+	h := New(store, kingdomstate.DefaultRules())
+
+	var state State
+	c.Assert(call(c, h, "POST", "/games", "", &state), Equals, http.StatusCreated)
+	for i := 0; i < 10; i++ {
+		c.Check(call(c, h, "POST", "/games", "", nil), Equals, http.StatusCreated)
+	}
+	now = now.Add(1000 * time.Hour)
+	store.Sweep()
+	c.Check(store.Len(), Equals, 11)
+	c.Check(call(c, h, "GET", "/games/"+state.ID, "", nil), Equals, http.StatusOK)
+}
+
+func (s *S) TestSweep(c *C) {
+	store := NewStore(time.Minute, 100)
+	var mu sync.Mutex
+	now := time.Unix(0, 0)
+	store.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	store.create(kingdomstate.DefaultRules(), 1, false)
+	store.Sweep()
+	c.Check(store.Len(), Equals, 1)
+
+	mu.Lock()
+	now = now.Add(2 * time.Minute)
+	mu.Unlock()
+	stop := store.SweepEvery(time.Millisecond)
+	defer stop()
+	for i := 0; i < 1000 && store.Len() > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	c.Check(store.Len(), Equals, 0)
+}
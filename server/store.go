@@ -0,0 +1,139 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"gomurabi/kingdomstate"
+	"sync"
+	"time"
+)
+
+// game is a game in the store. Its mutex guards the kingdom.
+type game struct {
+	mu       sync.Mutex
+	ks       kingdomstate.KingdomState
+	lastUsed time.Time
+}
+
+// ErrFull is returned when the store already holds as many games as it may.
+var ErrFull = errors.New("too many games in play")
+
+// Store holds up to a maximum number of games in memory, forgetting each once
+// it has gone unused for its time to live. A time to live or maximum of zero
+// or less is no limit. It is safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxGames int
+	games    map[string]*game
+	now      func() time.Time
+}
+
+func NewStore(ttl time.Duration, maxGames int) *Store {
+	return &Store{ttl: ttl, maxGames: maxGames, games: make(map[string]*game), now: time.Now}
+}
+
+// create starts a game and returns its id. Expired games are swept out first,
+// and ErrFull returned if there is still no room for another.
+func (s *Store) create(rules kingdomstate.Rules, seed int64, strict bool) (string, *game, error) {
+	g := &game{}
+	g.ks.SetupSeededState(rules, seed)
+	if strict {
+		g.ks.SetDecisionMode(kingdomstate.Strict)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.expire(now)
+	if s.maxGames > 0 && len(s.games) >= s.maxGames {
+		return "", nil, ErrFull
+	}
+	g.lastUsed = now
+	id := newID()
+	s.games[id] = g
+	return id, g, nil
+}
+
+// get returns the game with the id, unless there is none or it has expired.
+func (s *Store) get(id string) (*game, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.games[id]
+	if !ok {
+		return nil, false
+	}
+	now := s.now()
+	if s.expired(g, now) {
+		delete(s.games, id)
+		return nil, false
+	}
+	g.lastUsed = now
+	return g, true
+}
+
+func (s *Store) delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.games[id]
+	delete(s.games, id)
+	return ok
+}
+
+// Len is the number of games held, including any expired but not yet swept out.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.games)
+}
+
+// Sweep forgets every expired game.
+func (s *Store) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(s.now())
+}
+
+// SweepEvery sweeps out expired games at every interval until stop is called.
+func (s *Store) SweepEvery(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Sweep()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// expire forgets every game unused for longer than the time to live. The
+// store must be locked.
+func (s *Store) expire(now time.Time) {
+	for id, g := range s.games {
+		if s.expired(g, now) {
+			delete(s.games, id)
+		}
+	}
+}
+
+// expired tells whether the game has gone unused for longer than the time to live.
+func (s *Store) expired(g *game, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(g.lastUsed) > s.ttl
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
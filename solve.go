@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"gomurabi/solver"
+	"os"
+)
+
+// runSolve finds the optimal policy for the rules, saving it to policyFile if one is given.
+func runSolve(rules kingdomstate.Rules, threads int, policyFile string) {
+	cfg := solver.DefaultConfig()
+	cfg.Rules = rules
+	cfg.Threads = threads
+
+	policy, err := solver.Solve(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot solve: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Expected score=%.2f\n", policy.ExpectedScore())
+	if policyFile == "" {
+		return
+	}
+	if err := policy.Save(policyFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot save policy: %v\n", err)
+		os.Exit(1)
+	}
+}
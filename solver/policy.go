@@ -0,0 +1,174 @@
+package solver
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"os"
+)
+
+// A Policy is the table of decisions found by Solve. It implements
+// kingdomstate.Strategy, rounding the kingdom to the nearest state in its
+// table.
+type Policy struct {
+	rules    kingdomstate.Rules
+	grid     Grid
+	trade    []uint8 // acreage index to trade to, by year, state and price
+	food     []uint8 // index into foodLevels, by year and state once land is traded
+	expected float64
+}
+
+func newPolicy(rules kingdomstate.Rules, grid Grid) *Policy {
+	p := &Policy{rules: rules, grid: grid}
+	p.trade = make([]uint8, int(rules.TermYears)*p.cells()*p.prices())
+	p.food = make([]uint8, int(rules.TermYears)*p.cells())
+	return p
+}
+
+func (p *Policy) prices() int {
+	return int(p.rules.MaxPricePerAcre - p.rules.MinPricePerAcre + 1)
+}
+
+// cells is the number of states in a year, leaving out the price.
+func (p *Policy) cells() int {
+	return p.grid.populations() * p.grid.acreages() * p.grid.grains() * starvationBands
+}
+
+func (p *Policy) cell(population, acreage, grain, band int) int {
+	return ((population*p.grid.acreages()+acreage)*p.grid.grains()+grain)*starvationBands + band
+}
+
+func (p *Policy) yearCell(year uint, cell int) int {
+	return int(year-1)*p.cells() + cell
+}
+
+func (p *Policy) priceCell(year uint, cell, price int) int {
+	return p.yearCell(year, cell)*p.prices() + price
+}
+
+// Rules returns the rules the policy was solved for.
+func (p *Policy) Rules() kingdomstate.Rules {
+	return p.rules
+}
+
+// ExpectedScore is the expected score of playing the policy from the
+// initial state of its rules, as the solver reckons it on its grid.
+func (p *Policy) ExpectedScore() float64 {
+	return p.expected
+}
+
+// Decide implements kingdomstate.Strategy.
+func (p *Policy) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	year := ks.YearOfRule() + 1
+	if year > p.rules.TermYears {
+		year = p.rules.TermYears
+	}
+	population := ks.Population()
+	acreage := ks.Acreage()
+	grain := ks.Grain()
+	price := ks.NextYearPricePerAcre()
+	rating := ks.Evaluate()
+	band := starvationBand(rating.PercentStarved*float64(rating.Years), p.rules.TermYears)
+
+	pi := 0
+	if price > p.rules.MinPricePerAcre {
+		pi = int(price - p.rules.MinPricePerAcre)
+	}
+	if pi >= p.prices() {
+		pi = p.prices() - 1
+	}
+	i := nearest(population, p.grid.PopulationStep, p.grid.populations())
+	j := nearest(acreage, p.grid.AcreageStep, p.grid.acreages())
+	k := nearest(grain, p.grid.GrainStep, p.grid.grains())
+
+	// Trade as many acres as the table does between grid points
+	var d kingdomstate.Decision
+	t := int(p.trade[p.priceCell(year, p.cell(i, j, k, band), pi)])
+	switch {
+	case t > j:
+		d.AcresToBuy = min(uint(t-j)*p.grid.AcreageStep, grain/price)
+		grain -= d.AcresToBuy * price
+		acreage += d.AcresToBuy
+	case t < j:
+		d.AcresToSell = min(uint(j-t)*p.grid.AcreageStep, acreage)
+		grain += d.AcresToSell * price
+		acreage -= d.AcresToSell
+	}
+
+	j = nearest(acreage, p.grid.AcreageStep, p.grid.acreages())
+	k = nearest(grain, p.grid.GrainStep, p.grid.grains())
+	share := foodLevels[p.food[p.yearCell(year, p.cell(i, j, k, band))]]
+	d.GrainForFood = min(uint(share*float64(population*p.rules.GrainPerPerson)), grain)
+	grain -= d.GrainForFood
+	d.AcresToPlant = min(min(grain*p.rules.AcresPerBushel, acreage), population*p.rules.AcresPerPerson)
+	return d
+}
+
+// policyFile is how a policy is saved.
+type policyFile struct {
+	Rules         kingdomstate.Rules
+	Grid          Grid
+	FoodLevels    []float64
+	Trade         []byte
+	Food          []byte
+	ExpectedScore float64
+}
+
+// Save writes the policy to a gzipped JSON file.
+func (p *Policy) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	z := gzip.NewWriter(f)
+	err = json.NewEncoder(z).Encode(policyFile{p.rules, p.grid, foodLevels, p.trade, p.food, p.expected})
+	if cerr := z.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LoadPolicy reads a policy written by Save.
+func LoadPolicy(filename string) (*Policy, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	z, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	var pf policyFile
+	if err := json.NewDecoder(z).Decode(&pf); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := pf.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := pf.Grid.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	p := newPolicy(pf.Rules, pf.Grid)
+	if len(pf.Trade) != len(p.trade) || len(pf.Food) != len(p.food) {
+		return nil, fmt.Errorf("%s: policy table does not fit its grid", filename)
+	}
+	if fmt.Sprint(pf.FoodLevels) != fmt.Sprint(foodLevels) {
+		return nil, fmt.Errorf("%s: policy was solved with other food levels", filename)
+	}
+	for _, t := range pf.Trade {
+		if int(t) >= pf.Grid.acreages() {
+			return nil, fmt.Errorf("%s: policy table does not fit its grid", filename)
+		}
+	}
+	for _, level := range pf.Food {
+		if int(level) >= len(foodLevels) {
+			return nil, fmt.Errorf("%s: policy table does not fit its grid", filename)
+		}
+	}
+	p.trade, p.food, p.expected = pf.Trade, pf.Food, pf.ExpectedScore
+	return p, nil
+}
@@ -0,0 +1,338 @@
+// Package solver finds the strategy with the best expected end-of-reign
+// score by dynamic programming over a discretized kingdom.
+//
+// The state of the kingdom at the start of a year is its population, acreage
+// and grain, rounded to a grid, the price of land, which is already known,
+// and a band of the percent of the people starved so far. Working back from
+// the last year of the term, the solver finds for every state the trade of
+// land, and then the share of the people to feed, that maximize the expected
+// score over the known distributions of the harvest, rats, plague and the
+// next year's price. It always plants all it can.
+package solver
+
+import (
+	"errors"
+	"gomurabi/kingdomstate"
+	"sync"
+)
+
+// Grid is how finely the kingdom is discretized. Population, acreage and
+// grain are rounded to the nearest multiple of their step, and held at their
+// maximum.
+type Grid struct {
+	PopulationStep uint
+	MaxPopulation  uint
+	AcreageStep    uint
+	MaxAcreage     uint
+	GrainStep      uint
+	MaxGrain       uint
+}
+
+func DefaultGrid() Grid {
+	return Grid{
+		PopulationStep: 10,
+		MaxPopulation:  300,
+		AcreageStep:    200,
+		MaxAcreage:     5000,
+		GrainStep:      500,
+		MaxGrain:       15000,
+	}
+}
+
+func (g Grid) Validate() error {
+	switch {
+	case g.PopulationStep == 0 || g.AcreageStep == 0 || g.GrainStep == 0:
+		return errors.New("grid steps must be positive")
+	case g.MaxAcreage/g.AcreageStep >= 256:
+		return errors.New("grid has too many acreages")
+	}
+	return nil
+}
+
+func (g Grid) populations() int { return int(g.MaxPopulation/g.PopulationStep) + 1 }
+func (g Grid) acreages() int    { return int(g.MaxAcreage/g.AcreageStep) + 1 }
+func (g Grid) grains() int      { return int(g.MaxGrain/g.GrainStep) + 1 }
+
+// nearest is the index of the grid point nearest to v.
+func nearest(v, step uint, points int) int {
+	i := int((v + step/2) / step)
+	if i >= points {
+		i = points - 1
+	}
+	return i
+}
+
+// Shares of the people's needs the solver considers feeding them.
+var foodLevels = []float64{1, 0.9, 0.75, 0.5}
+
+// The average percent starved per year at which the rating tier changes,
+// as in kingdomstate.Rate. The starvation band of a state is the number of
+// these the total percent starved so far has passed, or one more once it is
+// past the last; the solver takes the total to be at the top of its band.
+var starvationEdges = []float64{0, 3, 10, 33}
+
+const starvationBands = 5
+
+func starvationBand(sumPercentStarved float64, term uint) int {
+	for b, edge := range starvationEdges {
+		if sumPercentStarved <= edge*float64(term) {
+			return b
+		}
+	}
+	return starvationBands - 1
+}
+
+func bandPercentStarved(band int, term uint) float64 {
+	if band < len(starvationEdges) {
+		return starvationEdges[band] * float64(term)
+	}
+	return 100 * float64(term)
+}
+
+// Config holds the parameters of the solver.
+type Config struct {
+	Rules     kingdomstate.Rules
+	Grid      Grid
+	RatLevels int // rat percentages are grouped into this many levels, or none if zero
+	Threads   int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Rules:     kingdomstate.DefaultRules(),
+		Grid:      DefaultGrid(),
+		RatLevels: 3,
+		Threads:   1,
+	}
+}
+
+// event is one combination of the random events of a year.
+type event struct {
+	yield      uint
+	ratPercent uint
+	plague     bool
+	chance     float64
+}
+
+// yearEvents lists every combination of the year's random events, with the
+// rat percentages grouped into ratLevels levels, each standing for the mean of
+// its group.
+func yearEvents(rules kingdomstate.Rules, ratLevels int) []event {
+	rats := []event{{ratPercent: 0, chance: 1 - rules.RatChance}}
+	n := int(rules.MaxRatPercent - rules.MinRatPercent + 1)
+	if ratLevels <= 0 || ratLevels > n {
+		ratLevels = n
+	}
+	for level := 0; level < ratLevels; level++ {
+		first, last := level*n/ratLevels, (level+1)*n/ratLevels
+		mean := rules.MinRatPercent + uint(first+last-1)/2
+		rats = append(rats, event{ratPercent: mean, chance: rules.RatChance * float64(last-first) / float64(n)})
+	}
+
+	var events []event
+	yields := rules.MaxYieldPerAcre - rules.MinYieldPerAcre + 1
+	for yield := rules.MinYieldPerAcre; yield <= rules.MaxYieldPerAcre; yield++ {
+		for _, r := range rats {
+			for _, plague := range []bool{false, true} {
+				chance := r.chance / float64(yields)
+				if plague {
+					chance *= rules.PlagueChance
+				} else {
+					chance *= 1 - rules.PlagueChance
+				}
+				if chance > 0 {
+					events = append(events, event{yield, r.ratPercent, plague, chance})
+				}
+			}
+		}
+	}
+	return events
+}
+
+// outcome is the kingdom at the end of a year.
+type outcome struct {
+	population     uint
+	grain          uint
+	percentStarved float64
+	stillInOffice  bool
+	removed        bool
+}
+
+// feedAndPlant plays out the rest of a year, after land has been traded, as
+// KingdomState.TallyUpYear does: feed as many people as the food allows,
+// plant all that can be planted, and suffer the events.
+func feedAndPlant(rules kingdomstate.Rules, year, population, acreage, grain, food uint, e event) outcome {
+	fed := min(min(grain, food)/rules.GrainPerPerson, population)
+	grain -= fed * rules.GrainPerPerson
+
+	planting := min(min(grain*rules.AcresPerBushel, acreage), population*rules.AcresPerPerson)
+	seed := min(grain, planting/rules.AcresPerBushel)
+	grain -= seed
+	afterPlanting := grain
+
+	grain += seed * rules.AcresPerBushel * e.yield
+	grain -= e.ratPercent * grain / 100
+
+	o := outcome{population: population}
+	if e.plague {
+		o.population -= o.population * rules.PlagueDeathPercent / 100
+	}
+	var starved uint
+	if o.population > fed {
+		starved = o.population - fed
+		o.percentStarved = 100 * float64(starved) / float64(population)
+		o.population -= starved
+	}
+	if o.population > 0 && starved == 0 {
+		o.population += (20*acreage+afterPlanting)/(100*o.population) + 1
+	}
+	o.grain = grain
+
+	limit := rules.StarvationLimitPercent * population / 100
+	o.stillInOffice = year < rules.TermYears && o.population > 0 && starved < limit
+	o.removed = o.population == 0 || starved >= limit
+	return o
+}
+
+func min(i, j uint) uint {
+	if i < j {
+		return i
+	}
+	return j
+}
+
+// Solve computes the optimal policy for the rules.
+func Solve(cfg Config) (*Policy, error) {
+	if err := cfg.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Grid.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Rules.Market.Enabled || cfg.Rules.Weather.Enabled || cfg.Rules.Epidemic.Enabled || cfg.Rules.Demographics.Enabled {
+		return nil, errors.New("the solver models neither the land market, the weather, the epidemic nor demographics")
+	}
+	if cfg.Threads < 1 {
+		cfg.Threads = 1
+	}
+
+	p := newPolicy(cfg.Rules, cfg.Grid)
+	s := &solver{Policy: p, events: yearEvents(cfg.Rules, cfg.RatLevels)}
+	for year := cfg.Rules.TermYears; year >= 1; year-- {
+		s.w = make([]float64, p.cells())
+		s.parallel(cfg.Threads, func(i int) { s.solveFeeding(year, i) })
+		ev := make([]float64, p.cells())
+		s.parallel(cfg.Threads, func(i int) { s.solveTrading(year, i, ev) })
+		s.next = ev
+	}
+	p.expected = s.next[p.cell(
+		nearest(cfg.Rules.InitialPopulation, cfg.Grid.PopulationStep, p.grid.populations()),
+		nearest(cfg.Rules.InitialAcreage, cfg.Grid.AcreageStep, p.grid.acreages()),
+		nearest(cfg.Rules.InitialGrain, cfg.Grid.GrainStep, p.grid.grains()),
+		0)]
+	return p, nil
+}
+
+// solver holds the work in progress of Solve.
+type solver struct {
+	*Policy
+	events []event
+	w      []float64 // value of each state of this year once land is traded
+	next   []float64 // expected value of each state at the start of next year
+}
+
+// parallel calls f for every population index, spread over threads.
+func (s *solver) parallel(threads int, f func(i int)) {
+	var wg sync.WaitGroup
+	n := s.grid.populations()
+	for t := 0; t < threads; t++ {
+		first, last := t*n/threads, (t+1)*n/threads
+		wg.Add(1)
+		go func() {
+			for i := first; i < last; i++ {
+				f(i)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+// value is the value of an outcome of the given year.
+func (s *solver) value(year, acreage uint, band int, o outcome) float64 {
+	sum := bandPercentStarved(band, s.rules.TermYears) + o.percentStarved
+	if !o.stillInOffice {
+		return kingdomstate.Rate(year, sum/float64(year), o.population, acreage, o.removed).Score()
+	}
+	return s.next[s.cell(
+		nearest(o.population, s.grid.PopulationStep, s.grid.populations()),
+		nearest(acreage, s.grid.AcreageStep, s.grid.acreages()),
+		nearest(o.grain, s.grid.GrainStep, s.grid.grains()),
+		starvationBand(sum, s.rules.TermYears))]
+}
+
+// solveFeeding finds the best share of the people to feed in every state
+// with population index i, once land has been traded.
+func (s *solver) solveFeeding(year uint, i int) {
+	population := uint(i) * s.grid.PopulationStep
+	for j := 0; j < s.grid.acreages(); j++ {
+		acreage := uint(j) * s.grid.AcreageStep
+		for k := 0; k < s.grid.grains(); k++ {
+			grain := uint(k) * s.grid.GrainStep
+			for band := 0; band < starvationBands; band++ {
+				best, bestLevel := -1.0, 0
+				for level, share := range foodLevels {
+					food := uint(share * float64(population*s.rules.GrainPerPerson))
+					var v float64
+					for _, e := range s.events {
+						o := feedAndPlant(s.rules, year, population, acreage, grain, food, e)
+						v += e.chance * s.value(year, acreage, band, o)
+					}
+					if v > best {
+						best, bestLevel = v, level
+					}
+				}
+				cell := s.cell(i, j, k, band)
+				s.w[cell] = best
+				s.food[s.yearCell(year, cell)] = uint8(bestLevel)
+			}
+		}
+	}
+}
+
+// solveTrading finds the best acreage to trade to in every state with
+// population index i, at every price, and the expected value of each state
+// over the prices.
+func (s *solver) solveTrading(year uint, i int, ev []float64) {
+	prices := s.prices()
+	for j := 0; j < s.grid.acreages(); j++ {
+		for k := 0; k < s.grid.grains(); k++ {
+			grain := uint(k) * s.grid.GrainStep
+			for band := 0; band < starvationBands; band++ {
+				cell := s.cell(i, j, k, band)
+				for pi := 0; pi < prices; pi++ {
+					price := s.rules.MinPricePerAcre + uint(pi)
+					best, bestTarget := -1.0, j
+					for t := 0; t < s.grid.acreages(); t++ {
+						after := grain
+						if t > j {
+							cost := uint(t-j) * s.grid.AcreageStep * price
+							if cost > grain {
+								break
+							}
+							after -= cost
+						} else {
+							after += uint(j-t) * s.grid.AcreageStep * price
+						}
+						v := s.w[s.cell(i, t, nearest(after, s.grid.GrainStep, s.grid.grains()), band)]
+						if v > best {
+							best, bestTarget = v, t
+						}
+					}
+					s.trade[s.priceCell(year, cell, pi)] = uint8(bestTarget)
+					ev[cell] += best / float64(prices)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package solver
+
+import (
+	"gomurabi/kingdomstate"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+// coarseConfig solves quickly enough for tests.
+func coarseConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Grid = Grid{
+		PopulationStep: 20,
+		MaxPopulation:  300,
+		AcreageStep:    400,
+		MaxAcreage:     4000,
+		GrainStep:      1000,
+		MaxGrain:       12000,
+	}
+	cfg.Threads = 3
+	return cfg
+}
+
+func (s *S) TestYearEvents(c *C) {
+	rules := kingdomstate.DefaultRules()
+	for _, levels := range []int{0, 1, 3, 21, 50} {
+		var total float64
+		var ratPercents = make(map[uint]bool)
+		for _, e := range yearEvents(rules, levels) {
+			total += e.chance
+			ratPercents[e.ratPercent] = true
+		}
+		c.Check(math.Abs(total-1) < 1e-9, Equals, true)
+		if levels == 0 || levels >= 21 {
+			c.Check(ratPercents, HasLen, 22)
+		} else {
+			c.Check(ratPercents, HasLen, levels+1)
+		}
+	}
+}
+
+// TestFeedAndPlant checks the solver's model of a year against the game.
+func (s *S) TestFeedAndPlant(c *C) {
+	randgen := rand.New(kingdomstate.NewSource(2))
+	for n := 0; n < 1000; n++ {
+		rules := kingdomstate.DefaultRules()
+		rules.TermYears = uint(randgen.Intn(2)) + 1
+		rules.InitialPopulation = uint(randgen.Intn(300))
+		rules.InitialAcreage = uint(randgen.Intn(4000))
+		rules.InitialGrain = uint(randgen.Intn(10000))
+		e := event{
+			yield:      uint(randgen.Intn(5)) + 1,
+			ratPercent: uint(randgen.Intn(31)),
+			plague:     randgen.Intn(2) == 1,
+		}
+		food := uint(randgen.Intn(int(rules.InitialGrain) + 1))
+		o := feedAndPlant(rules, 1, rules.InitialPopulation, rules.InitialAcreage, rules.InitialGrain, food, e)
+
+		var ks kingdomstate.KingdomState
+		ks.SetupInitialState(rules, kingdomstate.ScriptedEvents{Years: []kingdomstate.ScriptedYear{
+			{PricePerAcre: 20, YieldPerAcre: e.yield, RatPercent: e.ratPercent, Plague: e.plague},
+		}})
+		fed := min(food/rules.GrainPerPerson, rules.InitialPopulation)
+		grain := rules.InitialGrain - fed*rules.GrainPerPerson
+		plant := min(min(grain*rules.AcresPerBushel, rules.InitialAcreage), rules.InitialPopulation*rules.AcresPerPerson)
+		ks.TallyUpYear(0, 0, food, plant)
+
+		c.Check(o.population, Equals, ks.Population())
+		c.Check(o.grain, Equals, ks.Grain())
+		c.Check(o.percentStarved, Equals, ks.Evaluate().PercentStarved)
+		c.Check(o.stillInOffice, Equals, ks.StillInOffice())
+		reason := ks.GameOverReason()
+		c.Check(o.removed, Equals, reason == kingdomstate.EveryoneDied || reason == kingdomstate.MassStarvation)
+	}
+}
+
+// strictStrategy checks that every decision of a strategy can be carried out in full.
+type strictStrategy struct {
+	c        *C
+	strategy kingdomstate.Strategy
+}
+
+func (s strictStrategy) Decide(ks kingdomstate.KingdomState) kingdomstate.Decision {
+	d := s.strategy.Decide(ks)
+	s.c.Check(kingdomstate.ValidateDecision(ks, d), IsNil)
+	return d
+}
+
+func (s *S) TestSolve(c *C) {
+	cfg := coarseConfig()
+	p, err := Solve(cfg)
+	c.Assert(err, IsNil)
+
+	score := kingdomstate.MeanScore(cfg.Rules, strictStrategy{c, p}, 1000, 1)
+	c.Check(score > kingdomstate.MeanScore(cfg.Rules, kingdomstate.FeedThenPlantStrategy{}, 1000, 1)+10, Equals, true)
+	c.Check(math.Abs(score-p.ExpectedScore()) < 5, Equals, true, Commentf("played %.2f, expected %.2f", score, p.ExpectedScore()))
+
+	// The policy does not depend on the number of threads
+	cfg.Threads = 1
+	single, err := Solve(cfg)
+	c.Assert(err, IsNil)
+	c.Check(single.trade, DeepEquals, p.trade)
+	c.Check(single.food, DeepEquals, p.food)
+	c.Check(single.ExpectedScore(), Equals, p.ExpectedScore())
+}
+
+func (s *S) TestSolveShortTerm(c *C) {
+	cfg := coarseConfig()
+	cfg.Rules.TermYears = 1
+	p, err := Solve(cfg)
+	c.Assert(err, IsNil)
+
+	var ks kingdomstate.KingdomState
+	ks.SetupInitialState(cfg.Rules, kingdomstate.FixedEvents{})
+	kingdomstate.RunGame(&ks, p)
+	c.Check(ks.GameOverReason(), Equals, kingdomstate.TermFinished)
+}
+
+func (s *S) TestSolveInvalid(c *C) {
+	cfg := coarseConfig()
+	cfg.Grid.GrainStep = 0
+	_, err := Solve(cfg)
+	c.Check(err, NotNil)
+
+	cfg = coarseConfig()
+	cfg.Rules.TermYears = 0
+	_, err = Solve(cfg)
+	c.Check(err, NotNil)
+
+	cfg = coarseConfig()
+	cfg.Rules.Market.Enabled = true
+	_, err = Solve(cfg)
+	c.Check(err, NotNil)
+
+	cfg = coarseConfig()
+	cfg.Rules.Epidemic.Enabled = true
+	_, err = Solve(cfg)
+	c.Check(err, NotNil)
+
+	cfg = coarseConfig()
+	cfg.Rules.Demographics = kingdomstate.DefaultDemographicRules()
+	cfg.Rules.Demographics.Enabled = true
+	_, err = Solve(cfg)
+	c.Check(err, NotNil)
+}
+
+func (s *S) TestSaveAndLoadPolicy(c *C) {
+	cfg := coarseConfig()
+	cfg.Rules.TermYears = 3
+	p, err := Solve(cfg)
+	c.Assert(err, IsNil)
+
+	filename := filepath.Join(c.MkDir(), "policy.json.gz")
+	c.Assert(p.Save(filename), IsNil)
+	loaded, err := LoadPolicy(filename)
+	c.Assert(err, IsNil)
+	c.Check(loaded.Rules(), Equals, p.Rules())
+	c.Check(loaded.grid, Equals, p.grid)
+	c.Check(loaded.trade, DeepEquals, p.trade)
+	c.Check(loaded.food, DeepEquals, p.food)
+	c.Check(loaded.ExpectedScore(), Equals, p.ExpectedScore())
+
+	_, err = LoadPolicy(filepath.Join(c.MkDir(), "missing"))
+	c.Check(err, NotNil)
+}
@@ -0,0 +1,123 @@
+// Package stats summarizes the outcomes of many games.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// A Distribution counts how often each value was observed. Values are stored
+// as integers (after multiplying by the scale), so distributions collected by
+// different goroutines merge to exactly the same result in any order.
+type Distribution struct {
+	scale  float64
+	counts map[int64]uint64
+	n      uint64
+}
+
+// NewDistribution makes a distribution that keeps values to within 1/scale.
+func NewDistribution(scale float64) *Distribution {
+	return &Distribution{scale: scale, counts: make(map[int64]uint64)}
+}
+
+func (d *Distribution) Add(v float64) {
+	d.counts[int64(math.Floor(v*d.scale+0.5))]++
+	d.n++
+}
+
+func (d *Distribution) Merge(o *Distribution) {
+	for k, c := range o.counts {
+		d.counts[k] += c
+	}
+	d.n += o.n
+}
+
+func (d *Distribution) Count() uint64 {
+	return d.n
+}
+
+// keys returns the observed values, as stored, in increasing order.
+func (d *Distribution) keys() []int64 {
+	keys := make([]int64, 0, len(d.counts))
+	for k := range d.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func (d *Distribution) Mean() float64 {
+	if d.n == 0 {
+		return 0
+	}
+	var sum float64
+	for _, k := range d.keys() {
+		sum += float64(k) * float64(d.counts[k])
+	}
+	return sum / float64(d.n) / d.scale
+}
+
+// StdDev is the population standard deviation.
+func (d *Distribution) StdDev() float64 {
+	if d.n == 0 {
+		return 0
+	}
+	mean := d.Mean() * d.scale
+	var sum float64
+	for _, k := range d.keys() {
+		dev := float64(k) - mean
+		sum += dev * dev * float64(d.counts[k])
+	}
+	return math.Sqrt(sum/float64(d.n)) / d.scale
+}
+
+// Percentile returns the smallest value at or below which at least p percent of the observations fall.
+func (d *Distribution) Percentile(p float64) float64 {
+	if d.n == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(d.n)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	keys := d.keys()
+	for _, k := range keys {
+		seen += d.counts[k]
+		if seen >= rank {
+			return float64(k) / d.scale
+		}
+	}
+	return float64(keys[len(keys)-1]) / d.scale
+}
+
+func (d *Distribution) Median() float64 { return d.Percentile(50) }
+func (d *Distribution) Min() float64    { return d.Percentile(0) }
+func (d *Distribution) Max() float64    { return d.Percentile(100) }
+
+// A Bin of a histogram counts the observations from Low up to but not including High.
+type Bin struct {
+	Low, High float64
+	Count     uint64
+}
+
+// Histogram shares the observations out between at most n bins of equal
+// width, each a whole number of the smallest steps the distribution keeps.
+func (d *Distribution) Histogram(n int) []Bin {
+	if d.n == 0 || n < 1 {
+		return nil
+	}
+	keys := d.keys()
+	lo, hi := keys[0], keys[len(keys)-1]
+	width := (hi - lo + int64(n)) / int64(n)
+
+	bins := make([]Bin, (hi-lo)/width+1)
+	for i := range bins {
+		bins[i].Low = float64(lo+int64(i)*width) / d.scale
+		bins[i].High = float64(lo+int64(i+1)*width) / d.scale
+	}
+	for _, k := range keys {
+		bins[(k-lo)/width].Count += d.counts[k]
+	}
+	return bins
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"io"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// A Forecast is the spread of the outcomes of one year's decision over many
+// random futures.
+type Forecast struct {
+	Population *Distribution
+	Acreage    *Distribution
+	Grain      *Distribution
+	Reasons    map[kingdomstate.RemovalReason]uint64 // standing of the ruler at the end of the year
+}
+
+func newForecast() *Forecast {
+	return &Forecast{
+		Population: NewDistribution(1),
+		Acreage:    NewDistribution(1),
+		Grain:      NewDistribution(1),
+		Reasons:    make(map[kingdomstate.RemovalReason]uint64),
+	}
+}
+
+func (f *Forecast) add(r kingdomstate.YearReport) {
+	f.Population.Add(float64(r.EndOfYearPopulation))
+	f.Acreage.Add(float64(r.EndOfYearAcreage))
+	f.Grain.Add(float64(r.EndOfYearGrain))
+	f.Reasons[r.GameOverReason]++
+}
+
+func (f *Forecast) merge(o *Forecast) {
+	f.Population.Merge(o.Population)
+	f.Acreage.Merge(o.Acreage)
+	f.Grain.Merge(o.Grain)
+	for r, n := range o.Reasons {
+		f.Reasons[r] += n
+	}
+}
+
+// Futures is the number of futures simulated.
+func (f *Forecast) Futures() uint64 {
+	return f.Population.Count()
+}
+
+// RemovalProbability is the share of futures in which the ruler is thrown out
+// of office, or has nobody left to rule, by the end of the year.
+func (f *Forecast) RemovalProbability() float64 {
+	if f.Futures() == 0 {
+		return 0
+	}
+	removed := f.Reasons[kingdomstate.EveryoneDied] + f.Reasons[kingdomstate.MassStarvation]
+	return float64(removed) / float64(f.Futures())
+}
+
+// Print writes a summary of the forecast.
+func (f *Forecast) Print(w io.Writer) {
+	fmt.Fprintf(w, "Futures=%d\nRemoved=%.1f%%\n", f.Futures(), 100*f.RemovalProbability())
+	if f.Futures() == 0 {
+		return
+	}
+	printDistribution(w, "Population", f.Population)
+	printDistribution(w, "Acreage", f.Acreage)
+	printDistribution(w, "Grain", f.Grain)
+}
+
+// Simulate plays out the decision in n random futures of the kingdom, using
+// a thread for each CPU, and forecasts how the year will end. The kingdom
+// itself is left untouched. It returns the error ApplyDecision would if the
+// decision cannot be carried out.
+func Simulate(ks kingdomstate.KingdomState, d kingdomstate.Decision, n uint64) (*Forecast, error) {
+	return SimulateSeeded(ks, d, n, time.Now().UnixNano(), runtime.GOMAXPROCS(0))
+}
+
+// SimulateSeeded is like Simulate, with future i seeded with
+// kingdomstate.SubSeed(seed, i), so the forecast is the same however many
+// threads play it. It uses one thread if threads is less than one.
+func SimulateSeeded(ks kingdomstate.KingdomState, d kingdomstate.Decision, n uint64, seed int64, threads int) (*Forecast, error) {
+	trial := ks.Clone(kingdomstate.FixedEvents{})
+	if _, err := trial.ApplyDecision(d); err != nil {
+		return nil, err
+	}
+	if threads < 1 {
+		threads = 1
+	}
+
+	var wg sync.WaitGroup
+	forecasts := make([]*Forecast, threads)
+	for i := 0; i < threads; i++ {
+		forecasts[i] = newForecast()
+		first := uint64(i) * n / uint64(threads)
+		last := uint64(i+1) * n / uint64(threads)
+		wg.Add(1)
+		go simulate(&wg, ks, d, seed, first, last-first, forecasts[i])
+	}
+	wg.Wait()
+
+	total := newForecast()
+	for _, f := range forecasts {
+		total.merge(f)
+	}
+	return total, nil
+}
+
+// simulate plays out n futures, numbered from first, adding each to f.
+func simulate(wg *sync.WaitGroup, ks kingdomstate.KingdomState, d kingdomstate.Decision, seed int64, first, n uint64, f *Forecast) {
+	randgen := rand.New(kingdomstate.NewSource(seed))
+	events := kingdomstate.NewRandomEvents(ks.Rules(), randgen)
+	for i := first; i < first+n; i++ {
+		randgen.Seed(kingdomstate.SubSeed(seed, i))
+		future := ks.Clone(events)
+		r, _ := future.ApplyDecision(d)
+		f.add(r)
+	}
+	wg.Done()
+}
@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"io"
+	"strings"
+)
+
+// Outcome is how a single game ended.
+type Outcome struct {
+	YearsSurvived   uint
+	FinalPopulation uint
+	AcresPerPerson  float64 // zero if nobody is left
+	TotalStarved    uint
+	Reason          kingdomstate.RemovalReason
+}
+
+func OutcomeOf(ks kingdomstate.KingdomState) Outcome {
+	o := Outcome{
+		YearsSurvived:   ks.YearOfRule(),
+		FinalPopulation: ks.Population(),
+		TotalStarved:    ks.TotalStarvationVictims(),
+		Reason:          ks.GameOverReason(),
+	}
+	if ks.Population() > 0 {
+		o.AcresPerPerson = float64(ks.Acreage()) / float64(ks.Population())
+	}
+	return o
+}
+
+// A Collector gathers the outcomes of games. Each goroutine should have its
+// own, and merge it into a single one once all the games are played.
+type Collector struct {
+	YearsSurvived   *Distribution
+	FinalPopulation *Distribution
+	AcresPerPerson  *Distribution
+	TotalStarved    *Distribution
+	Reasons         map[kingdomstate.RemovalReason]uint64
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		YearsSurvived:   NewDistribution(1),
+		FinalPopulation: NewDistribution(1),
+		AcresPerPerson:  NewDistribution(100),
+		TotalStarved:    NewDistribution(1),
+		Reasons:         make(map[kingdomstate.RemovalReason]uint64),
+	}
+}
+
+func (c *Collector) Add(o Outcome) {
+	c.YearsSurvived.Add(float64(o.YearsSurvived))
+	c.FinalPopulation.Add(float64(o.FinalPopulation))
+	c.AcresPerPerson.Add(o.AcresPerPerson)
+	c.TotalStarved.Add(float64(o.TotalStarved))
+	c.Reasons[o.Reason]++
+}
+
+func (c *Collector) Merge(o *Collector) {
+	c.YearsSurvived.Merge(o.YearsSurvived)
+	c.FinalPopulation.Merge(o.FinalPopulation)
+	c.AcresPerPerson.Merge(o.AcresPerPerson)
+	c.TotalStarved.Merge(o.TotalStarved)
+	for r, n := range o.Reasons {
+		c.Reasons[r] += n
+	}
+}
+
+func (c *Collector) Games() uint64 {
+	return c.YearsSurvived.Count()
+}
+
+const histogramBins = 10
+const histogramWidth = 50
+
+// Print writes a summary of every distribution, with a histogram of each.
+func (c *Collector) Print(w io.Writer) {
+	games := c.Games()
+	fmt.Fprintf(w, "Games=%d\n", games)
+	if games == 0 {
+		return
+	}
+	for r := kingdomstate.TermFinished; r <= kingdomstate.MassStarvation; r++ {
+		fmt.Fprintf(w, "  %-16s %10d (%5.1f%%)\n", r.String()+":", c.Reasons[r], 100*float64(c.Reasons[r])/float64(games))
+	}
+
+	printDistribution(w, "Years survived", c.YearsSurvived)
+	printDistribution(w, "Final population", c.FinalPopulation)
+	printDistribution(w, "Acres per person", c.AcresPerPerson)
+	printDistribution(w, "Total starved", c.TotalStarved)
+}
+
+func printDistribution(w io.Writer, name string, d *Distribution) {
+	fmt.Fprintf(w, "\n%s: mean=%.2f median=%.2f stddev=%.2f\n", name, d.Mean(), d.Median(), d.StdDev())
+	fmt.Fprintf(w, "  min=%.2f p5=%.2f p25=%.2f p75=%.2f p95=%.2f max=%.2f\n",
+		d.Min(), d.Percentile(5), d.Percentile(25), d.Percentile(75), d.Percentile(95), d.Max())
+
+	bins := d.Histogram(histogramBins)
+	var most uint64
+	for _, b := range bins {
+		if b.Count > most {
+			most = b.Count
+		}
+	}
+	for _, b := range bins {
+		bar := strings.Repeat("#", int(b.Count*histogramWidth/most))
+		fmt.Fprintf(w, "  %9.2f - %9.2f | %-*s %d\n", b.Low, b.High, histogramWidth, bar, b.Count)
+	}
+}
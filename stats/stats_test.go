@@ -0,0 +1,172 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	. "github.com/go-check/check"
+	"gomurabi/kingdomstate"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (s *S) TestDistribution(c *C) {
+	d := NewDistribution(1)
+	for _, v := range []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10} {
+		d.Add(v)
+	}
+	c.Check(d.Count(), Equals, uint64(10))
+	c.Check(d.Mean(), Equals, 5.5)
+	c.Check(math.Abs(d.StdDev()-math.Sqrt(8.25)) < 1e-9, Equals, true)
+	c.Check(d.Median(), Equals, 5.0)
+	c.Check(d.Percentile(90), Equals, 9.0)
+	c.Check(d.Min(), Equals, 1.0)
+	c.Check(d.Max(), Equals, 10.0)
+
+	bins := d.Histogram(5)
+	c.Assert(bins, HasLen, 5)
+	c.Check(bins[0], Equals, Bin{1, 3, 2})
+	c.Check(bins[4], Equals, Bin{9, 11, 2})
+}
+
+func (s *S) TestDistributionScale(c *C) {
+	d := NewDistribution(100)
+	d.Add(1.234)
+	d.Add(2.345)
+	c.Check(d.Min(), Equals, 1.23)
+	c.Check(d.Max(), Equals, 2.35)
+}
+
+func (s *S) TestEmptyDistribution(c *C) {
+	d := NewDistribution(1)
+	c.Check(d.Mean(), Equals, 0.0)
+	c.Check(d.Median(), Equals, 0.0)
+	c.Check(d.Histogram(10), HasLen, 0)
+}
+
+func (s *S) TestMergeIsOrderIndependent(c *C) {
+	randgen := rand.New(rand.NewSource(1))
+	var parts []*Distribution
+	all := NewDistribution(100)
+	for i := 0; i < 4; i++ {
+		d := NewDistribution(100)
+		for j := 0; j < 1000; j++ {
+			v := randgen.Float64() * 30
+			d.Add(v)
+			all.Add(v)
+		}
+		parts = append(parts, d)
+	}
+
+	forward := NewDistribution(100)
+	backward := NewDistribution(100)
+	for i := range parts {
+		forward.Merge(parts[i])
+		backward.Merge(parts[len(parts)-1-i])
+	}
+	for _, d := range []*Distribution{forward, backward} {
+		c.Check(d.Mean(), Equals, all.Mean())
+		c.Check(d.StdDev(), Equals, all.StdDev())
+		c.Check(d.Percentile(95), Equals, all.Percentile(95))
+	}
+}
+
+func (s *S) TestCollector(c *C) {
+	all := NewCollector()
+	parts := []*Collector{NewCollector(), NewCollector()}
+	for i := 0; i < 200; i++ {
+		var ks kingdomstate.KingdomState
+
+		ks.SetupSeededState(kingdomstate.DefaultRules(), kingdomstate.SubSeed(3, uint64(i)))
+		kingdomstate.RunGame(&ks, kingdomstate.FeedThenPlantStrategy{})
+		o := OutcomeOf(ks)
+		c.Check(o.YearsSurvived, Equals, ks.YearOfRule())
+		c.Check(o.Reason == kingdomstate.StillRuling, Equals, false)
+		all.Add(o)
+		parts[i%2].Add(o)
+	}
+
+	merged := NewCollector()
+	merged.Merge(parts[0])
+	merged.Merge(parts[1])
+	c.Check(merged.Games(), Equals, uint64(200))
+	c.Check(merged.FinalPopulation.Mean(), Equals, all.FinalPopulation.Mean())
+	c.Check(merged.Reasons, DeepEquals, all.Reasons)
+
+	var buf bytes.Buffer
+	merged.Print(&buf)
+	c.Check(strings.HasPrefix(buf.String(), "Games=200\n"), Equals, true)
+	c.Check(strings.Contains(buf.String(), "Acres per person: mean="), Equals, true)
+}
+
+func (s *S) TestOutcomeOfEmptyKingdom(c *C) {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupInitialState(kingdomstate.DefaultRules(), kingdomstate.FixedEvents{})
+	ks.TallyUpYear(0, 0, 0, 0)
+	o := OutcomeOf(ks)
+	c.Check(o.AcresPerPerson, Equals, 0.0)
+	c.Check(o.TotalStarved, Equals, uint(100))
+	c.Check(o.Reason, Equals, kingdomstate.EveryoneDied)
+}
+
+func (s *S) TestSimulate(c *C) {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupSeededState(kingdomstate.DefaultRules(), 11)
+	d := kingdomstate.Decision{GrainForFood: 2000, AcresToPlant: 1000}
+	f, err := SimulateSeeded(ks, d, 1000, 3, 1)
+	c.Assert(err, IsNil)
+	c.Check(f.Futures(), Equals, uint64(1000))
+	c.Check(f.RemovalProbability(), Equals, 0.0)
+	c.Check(f.Acreage.Min(), Equals, 1000.0)
+	c.Check(f.Acreage.Max(), Equals, 1000.0)
+	// Plague halves the people in some futures but not in others
+	c.Check(f.Population.Min() < 60, Equals, true)
+	c.Check(f.Population.Max() > 100, Equals, true)
+	c.Check(ks.YearOfRule(), Equals, uint(0))
+	h, _ := ks.History()
+	c.Check(h.Years, HasLen, 0)
+
+	// The forecast does not depend on the number of threads
+	parallel, err := SimulateSeeded(ks, d, 1000, 3, 4)
+	c.Assert(err, IsNil)
+	c.Check(parallel.Grain.Mean(), Equals, f.Grain.Mean())
+	c.Check(parallel.Population.StdDev(), Equals, f.Population.StdDev())
+	c.Check(parallel.Reasons, DeepEquals, f.Reasons)
+	single, err := SimulateSeeded(ks, d, 1000, 3, 0)
+	c.Assert(err, IsNil)
+	c.Check(single.Futures(), Equals, uint64(1000))
+	c.Check(single.Grain.Mean(), Equals, f.Grain.Mean())
+
+	// Starving everyone removes the ruler in every future
+	f, err = Simulate(ks, kingdomstate.Decision{}, 100)
+	c.Assert(err, IsNil)
+	c.Check(f.RemovalProbability(), Equals, 1.0)
+
+	var buf bytes.Buffer
+	f.Print(&buf)
+	c.Check(strings.HasPrefix(buf.String(), "Futures=100\nRemoved=100.0%\n"), Equals, true)
+}
+
+func (s *S) TestSimulateImpossibleDecision(c *C) {
+	var ks kingdomstate.KingdomState
+
+	ks.SetupInitialState(kingdomstate.DefaultRules(), kingdomstate.FixedEvents{})
+	ks.SetDecisionMode(kingdomstate.Strict)
+	_, err := Simulate(ks, kingdomstate.Decision{GrainForFood: 5000}, 10)
+	c.Check(errors.Is(err, kingdomstate.ErrNotEnoughGrain), Equals, true)
+
+	ks.SetDecisionMode(kingdomstate.Lenient)
+	ks.TallyUpYear(0, 0, 0, 0)
+	_, err = Simulate(ks, kingdomstate.Decision{}, 10)
+	c.Check(errors.Is(err, kingdomstate.ErrGameOver), Equals, true)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"gomurabi/kingdomstate"
+	"gomurabi/mcts"
+	"sort"
+)
+
+// Strategies selectable with the -strategy flag.
+var strategies = map[string]kingdomstate.Strategy{
+	"fixed":             kingdomstate.FixedStrategy{Decision: kingdomstate.Decision{AcresToSell: 50, GrainForFood: 2000, AcresToPlant: 10}},
+	"feed-then-plant":   kingdomstate.FeedThenPlantStrategy{},
+	"buy-low-sell-high": kingdomstate.BuyLowSellHighStrategy{BuyBelow: 19, SellAbove: 24},
+	"mcts":              mcts.Player{Config: mcts.DefaultConfig()},
+}
+
+func strategyNames() []string {
+	var names []string
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gomurabi/kingdomstate"
+	"gomurabi/tournament"
+	"os"
+	"strings"
+)
+
+// tournamentGames is how many games each strategy plays in a tournament
+// unless -games says otherwise. Every strategy plays every game, so a
+// tournament takes many times as long as simulating one strategy.
+const tournamentGames = 10000
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// runTournament ranks the named strategies, or all of them, by playing each on the same games.
+func runTournament(rules kingdomstate.Rules, names string, games uint64, seed int64, threads int) {
+	var list []string
+	if names == "all" {
+		list = strategyNames()
+	} else {
+		list = strings.Split(names, ",")
+	}
+
+	var entries []tournament.Entry
+	seen := make(map[string]bool)
+	for _, name := range list {
+		name = strings.TrimSpace(name)
+		strategy, ok := strategies[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Unknown strategy %q\n", name)
+			os.Exit(2)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		entries = append(entries, tournament.Entry{Name: name, Strategy: strategy})
+	}
+
+	fmt.Printf("Threads=%d\nSeed=%d\n", threads, seed)
+	cfg := tournament.Config{Rules: rules, Games: games, Seed: seed, Threads: threads}
+	tournament.Play(cfg, entries).Print(os.Stdout)
+}
@@ -0,0 +1,280 @@
+// Package tournament ranks strategies by playing every one of them on the
+// same random futures.
+//
+// Game i of a tournament is seeded with kingdomstate.SubSeed(Seed, i) for
+// every strategy, and the kingdom draws its events in the same order each
+// year whatever the ruler decides, so every strategy faces the same prices,
+// harvests, rats and plagues in game i. Comparing the strategies game by game
+// then cancels out the luck of the draw, and the paired differences need far
+// fewer games to tell strategies apart than separate runs would.
+package tournament
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"gomurabi/stats"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// z is the normal quantile of the two-sided 95% confidence intervals.
+const z = 1.96
+
+// scoreScale keeps scores to within a hundredth of a point.
+const scoreScale = 100
+
+// Entry is a strategy in the tournament.
+type Entry struct {
+	Name     string
+	Strategy kingdomstate.Strategy // shared by every thread, so it must be safe for concurrent use
+}
+
+// Config holds the parameters of a tournament.
+type Config struct {
+	Rules   kingdomstate.Rules
+	Games   uint64
+	Seed    int64
+	Threads int
+}
+
+// Interval is an estimate of a mean with its 95% confidence interval.
+type Interval struct {
+	Mean float64
+	Low  float64
+	High float64
+}
+
+// interval estimates the mean of the distribution's population.
+func interval(d *stats.Distribution) Interval {
+	n := float64(d.Count())
+	mean := d.Mean()
+	if n < 2 {
+		return Interval{mean, mean, mean}
+	}
+	halfWidth := z * d.StdDev() * math.Sqrt(n/(n-1)) / math.Sqrt(n)
+	return Interval{mean, mean - halfWidth, mean + halfWidth}
+}
+
+// Excludes reports whether the value lies outside the interval.
+func (i Interval) Excludes(v float64) bool {
+	return v < i.Low || v > i.High
+}
+
+// Standing is how a strategy fared in the tournament.
+type Standing struct {
+	Name  string
+	Score Interval // mean score over the games
+	Wins  int      // strategies it scores significantly better than
+}
+
+// Comparison is the paired difference between the scores of two strategies.
+type Comparison struct {
+	A, B       string
+	Difference Interval // A's score minus B's, game by game
+	AWins      uint64   // games A scores more than B
+	BWins      uint64
+}
+
+// Significant reports whether one strategy is better than the other at 95% confidence.
+func (c Comparison) Significant() bool {
+	return c.Difference.Excludes(0)
+}
+
+// Ranking is the outcome of a tournament, with the strategies best first.
+type Ranking struct {
+	Games       uint64
+	Standings   []Standing
+	Comparisons []Comparison // of each pair of strategies, in the order of the standings
+}
+
+// tally gathers the scores of some of the games. Each goroutine has its own.
+type tally struct {
+	scores      []*stats.Distribution // by entry
+	differences []*stats.Distribution // by pair
+	wins        [][2]uint64           // by pair
+}
+
+func newTally(entries int) *tally {
+	t := &tally{}
+	for i := 0; i < entries; i++ {
+		t.scores = append(t.scores, stats.NewDistribution(scoreScale))
+	}
+	for i := 0; i < entries*(entries-1)/2; i++ {
+		t.differences = append(t.differences, stats.NewDistribution(scoreScale))
+	}
+	t.wins = make([][2]uint64, len(t.differences))
+	return t
+}
+
+func (t *tally) add(scores []float64) {
+	p := 0
+	for i, a := range scores {
+		t.scores[i].Add(a)
+		for _, b := range scores[i+1:] {
+			t.differences[p].Add(a - b)
+			switch {
+			case a > b:
+				t.wins[p][0]++
+			case b > a:
+				t.wins[p][1]++
+			}
+			p++
+		}
+	}
+}
+
+func (t *tally) merge(o *tally) {
+	for i, d := range o.scores {
+		t.scores[i].Merge(d)
+	}
+	for p, d := range o.differences {
+		t.differences[p].Merge(d)
+		t.wins[p][0] += o.wins[p][0]
+		t.wins[p][1] += o.wins[p][1]
+	}
+}
+
+// Play plays Config.Games games with each strategy and ranks them.
+func Play(cfg Config, entries []Entry) *Ranking {
+	threads := cfg.Threads
+	if threads < 1 {
+		threads = 1
+	}
+
+	var wg sync.WaitGroup
+	tallies := make([]*tally, threads)
+	for i := 0; i < threads; i++ {
+		tallies[i] = newTally(len(entries))
+		first := uint64(i) * cfg.Games / uint64(threads)
+		last := uint64(i+1) * cfg.Games / uint64(threads)
+		wg.Add(1)
+		go play(&wg, cfg, entries, first, last-first, tallies[i])
+	}
+	wg.Wait()
+	total := newTally(len(entries))
+	for _, t := range tallies {
+		total.merge(t)
+	}
+	return ranking(cfg.Games, entries, total)
+}
+
+// play plays n games, numbered from first, with every strategy.
+func play(wg *sync.WaitGroup, cfg Config, entries []Entry, first, n uint64, t *tally) {
+	randgen := rand.New(kingdomstate.NewSource(cfg.Seed))
+	events := kingdomstate.NewRandomEvents(cfg.Rules, randgen)
+	scores := make([]float64, len(entries))
+	for i := first; i < first+n; i++ {
+		for e, entry := range entries {
+			var ks kingdomstate.KingdomState
+
+			randgen.Seed(kingdomstate.SubSeed(cfg.Seed, i))
+			ks.SetupInitialState(cfg.Rules, events)
+			kingdomstate.RunGame(&ks, entry.Strategy)
+			scores[e] = ks.Evaluate().Score()
+		}
+		t.add(scores)
+	}
+	wg.Done()
+}
+
+func ranking(games uint64, entries []Entry, t *tally) *Ranking {
+	r := &Ranking{Games: games}
+	standings := make([]Standing, len(entries))
+	for i, e := range entries {
+		standings[i] = Standing{Name: e.Name, Score: interval(t.scores[i])}
+	}
+
+	// Each comparison, with the entries it compares
+	type pair struct {
+		a, b int
+		c    Comparison
+	}
+	var pairs []pair
+	p := 0
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			c := Comparison{
+				A:          entries[i].Name,
+				B:          entries[j].Name,
+				Difference: interval(t.differences[p]),
+				AWins:      t.wins[p][0],
+				BWins:      t.wins[p][1],
+			}
+			if c.Significant() {
+				if c.Difference.Mean > 0 {
+					standings[i].Wins++
+				} else {
+					standings[j].Wins++
+				}
+			}
+			pairs = append(pairs, pair{i, j, c})
+			p++
+		}
+	}
+
+	// Rank by significant wins, then by mean score
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := standings[order[a]], standings[order[b]]
+		if sa.Wins != sb.Wins {
+			return sa.Wins > sb.Wins
+		}
+		return sa.Score.Mean > sb.Score.Mean
+	})
+	rank := make([]int, len(entries))
+	for i, e := range order {
+		rank[e] = i
+		r.Standings = append(r.Standings, standings[e])
+	}
+
+	// Put each comparison the better-ranked way round, in the order of the standings
+	for i, p := range pairs {
+		if rank[p.b] < rank[p.a] {
+			c := p.c
+			pairs[i] = pair{p.b, p.a, Comparison{
+				A:          c.B,
+				B:          c.A,
+				Difference: Interval{-c.Difference.Mean, -c.Difference.High, -c.Difference.Low},
+				AWins:      c.BWins,
+				BWins:      c.AWins,
+			}}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if rank[pairs[i].a] != rank[pairs[j].a] {
+			return rank[pairs[i].a] < rank[pairs[j].a]
+		}
+		return rank[pairs[i].b] < rank[pairs[j].b]
+	})
+	for _, p := range pairs {
+		r.Comparisons = append(r.Comparisons, p.c)
+	}
+	return r
+}
+
+// Print writes the standings and the paired comparisons.
+func (r *Ranking) Print(w io.Writer) {
+	fmt.Fprintf(w, "Games=%d\n", r.Games)
+	fmt.Fprintf(w, "%4s  %-20s %8s  %-17s %4s\n", "Rank", "Strategy", "Score", "95% CI", "Wins")
+	for i, s := range r.Standings {
+		fmt.Fprintf(w, "%4d  %-20s %8.2f  [%6.2f, %6.2f] %4d\n", i+1, s.Name, s.Score.Mean, s.Score.Low, s.Score.High, s.Wins)
+	}
+	if len(r.Comparisons) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nPaired differences:\n")
+	for _, c := range r.Comparisons {
+		verdict := "not significant"
+		if c.Significant() {
+			verdict = "significant"
+		}
+		fmt.Fprintf(w, "  %-20s - %-20s %+8.2f  [%+7.2f, %+7.2f]  won %d, lost %d  %s\n",
+			c.A, c.B, c.Difference.Mean, c.Difference.Low, c.Difference.High, c.AWins, c.BWins, verdict)
+	}
+}
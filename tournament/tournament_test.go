@@ -0,0 +1,108 @@
+package tournament
+
+import (
+	"bytes"
+	"gomurabi/kingdomstate"
+	"math/rand"
+	"strings"
+	"testing"
+
+	. "github.com/go-check/check"
+)
+
+// Hook up gocheck into the gotest runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func entries() []Entry {
+	return []Entry{
+		{"fixed", kingdomstate.FixedStrategy{Decision: kingdomstate.Decision{AcresToSell: 50, GrainForFood: 2000, AcresToPlant: 10}}},
+		{"feed-then-plant", kingdomstate.FeedThenPlantStrategy{}},
+		{"again", kingdomstate.FeedThenPlantStrategy{}},
+		{"buy-low-sell-high", kingdomstate.BuyLowSellHighStrategy{BuyBelow: 19, SellAbove: 24}},
+	}
+}
+
+func config(threads int) Config {
+	return Config{Rules: kingdomstate.DefaultRules(), Games: 2000, Seed: 5, Threads: threads}
+}
+
+func (s *S) TestRanking(c *C) {
+	r := Play(config(2), entries())
+
+	c.Assert(r.Standings, HasLen, 4)
+	c.Check(r.Standings[0].Name, Equals, "fixed")
+	c.Check(r.Standings[0].Wins, Equals, 3)
+	c.Check(r.Standings[3].Name, Equals, "buy-low-sell-high")
+	c.Check(r.Standings[3].Wins, Equals, 0)
+	for i := 1; i < len(r.Standings); i++ {
+		c.Check(r.Standings[i-1].Wins >= r.Standings[i].Wins, Equals, true)
+	}
+	for _, s := range r.Standings {
+		c.Check(s.Score.Low <= s.Score.Mean && s.Score.Mean <= s.Score.High, Equals, true, Commentf(s.Name))
+	}
+
+	c.Assert(r.Comparisons, HasLen, 6)
+	for _, cmp := range r.Comparisons {
+		c.Check(cmp.Difference.Mean >= 0, Equals, true, Commentf("%s - %s", cmp.A, cmp.B))
+		if cmp.A+cmp.B == "feed-then-plantagain" || cmp.A+cmp.B == "againfeed-then-plant" {
+			// The same strategy on the same futures plays the same games
+			c.Check(cmp.Difference, Equals, Interval{})
+			c.Check(cmp.AWins+cmp.BWins, Equals, uint64(0))
+			c.Check(cmp.Significant(), Equals, false)
+		}
+		if cmp.B == "buy-low-sell-high" {
+			c.Check(cmp.Significant(), Equals, true, Commentf(cmp.A))
+		}
+	}
+
+	var buf bytes.Buffer
+	r.Print(&buf)
+	c.Check(strings.Contains(buf.String(), "Paired differences"), Equals, true)
+}
+
+func (s *S) TestThreadsDoNotMatter(c *C) {
+	c.Check(Play(config(3), entries()), DeepEquals, Play(config(1), entries()))
+}
+
+func (s *S) TestPairingNarrowsIntervals(c *C) {
+	r := Play(config(1), entries()[1:])
+	var standings = map[string]Standing{}
+	for _, s := range r.Standings {
+		standings[s.Name] = s
+	}
+	for _, cmp := range r.Comparisons {
+		if cmp.A != "buy-low-sell-high" && cmp.B != "buy-low-sell-high" {
+			continue
+		}
+		paired := cmp.Difference.High - cmp.Difference.Low
+		a, b := standings[cmp.A].Score, standings[cmp.B].Score
+		unpaired := (a.High - a.Low) + (b.High - b.Low)
+		c.Check(paired < unpaired/2, Equals, true, Commentf("paired %.2f, unpaired %.2f", paired, unpaired))
+	}
+}
+
+// Every strategy faces the same events in the same game.
+func (s *S) TestCommonRandomNumbers(c *C) {
+	rules := kingdomstate.DefaultRules()
+	var scripts []kingdomstate.ScriptedEvents
+	for _, e := range entries() {
+		var ks kingdomstate.KingdomState
+		events := kingdomstate.NewRecordingEvents(kingdomstate.NewRandomEvents(rules, rand.New(kingdomstate.NewSource(kingdomstate.SubSeed(5, 9)))))
+		ks.SetupInitialState(rules, events)
+		kingdomstate.RunGame(&ks, e.Strategy)
+		scripts = append(scripts, events.Script())
+	}
+	for i, script := range scripts[1:] {
+		n := len(script.Years)
+		if len(scripts[0].Years) < n {
+			n = len(scripts[0].Years)
+		}
+		// The last year of the shorter game is only as far as the price of land
+		c.Check(script.Years[:n-1], DeepEquals, scripts[0].Years[:n-1], Commentf(entries()[i+1].Name))
+		c.Check(script.Years[n-1].PricePerAcre, Equals, scripts[0].Years[n-1].PricePerAcre, Commentf(entries()[i+1].Name))
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"gomurabi/kingdomstate"
+	"gomurabi/qlearn"
+	"os"
+)
+
+// runTrainQ trains a Q-learning agent under the rules and saves it to filename.
+func runTrainQ(rules kingdomstate.Rules, episodes int, seed int64, filename string) {
+	cfg := qlearn.DefaultConfig()
+	cfg.Rules = rules
+	cfg.Episodes = episodes
+	cfg.Seed = seed
+
+	agent := qlearn.Train(cfg, func(p qlearn.Progress) {
+		fmt.Printf("Episodes %9d: mean return=%8.2f epsilon=%.3f rate=%.3f\n", p.Episodes, p.MeanReturn, p.Epsilon, p.LearningRate)
+	})
+	if err := agent.Save(filename); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot save Q-table: %v\n", err)
+		os.Exit(1)
+	}
+}